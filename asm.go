@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Neffats/Chip8/src/asm"
+)
+
+//asmCommand assembles a source file into a program, usage: chip8 asm [-o rom.ch8] rom.asm
+func asmCommand(args []string) error {
+	fs := flag.NewFlagSet("asm", flag.ExitOnError)
+	out := fs.String("o", "", "Output file, defaults to the source file with a .ch8 extension.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: chip8 asm [-o rom.ch8] rom.asm\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if *out == "" {
+		*out = strings.TrimSuffix(fs.Arg(0), filepath.Ext(fs.Arg(0))) + ".ch8"
+	}
+	if samePath(*out, fs.Arg(0)) {
+		return fmt.Errorf("output file %s would overwrite the source, use -o to pick another", *out)
+	}
+
+	src, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("could not open source: %v", err)
+	}
+	defer src.Close()
+
+	program, err := asm.Assemble(src)
+	if err != nil {
+		return fmt.Errorf("%s:\n%v", fs.Arg(0), err)
+	}
+
+	err = ioutil.WriteFile(*out, program, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write program: %v", err)
+	}
+	return nil
+}
+
+//samePath reports whether a and b name the same file.
+func samePath(a string, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
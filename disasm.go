@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Neffats/Chip8/src/disasm"
+)
+
+//disasmCommand prints a listing of a program, usage: chip8 disasm rom.ch8
+func disasmCommand(args []string) error {
+	fs := flag.NewFlagSet("disasm", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: chip8 disasm rom.ch8\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	program, err := GetFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return disasm.Listing(os.Stdout, program)
+}
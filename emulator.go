@@ -0,0 +1,296 @@
+//go:build !nosdl
+// +build !nosdl
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	chip8 "github.com/Neffats/Chip8/src"
+	"github.com/Neffats/Chip8/src/romdb"
+)
+
+//loadConfig reads the config file, or the default one if name is empty.
+//A missing default config is fine, everything is left at its default.
+func loadConfig(name string) (*chip8.Config, error) {
+	if name == "" {
+		path, err := chip8.DefaultConfigPath()
+		if err != nil {
+			return &chip8.Config{}, nil
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return &chip8.Config{}, nil
+		}
+		name = path
+	}
+	return chip8.LoadConfig(name)
+}
+
+//runEmulator runs a program in an SDL window, the emulator's flags are read from the command line.
+func runEmulator() {
+	program := flag.String("p", "", "Chip8 program file.")
+	ips := flag.Int("ips", chip8.DefaultIPS, "Clock speed in instructions per second. Known ROMs default to their recommended speed.")
+	debug := flag.Bool("debug", false, "Start the interactive debugger instead of running the program.")
+	state := flag.String("state", "", "Save state file for the F5/F9 hotkeys, defaults to the program file with .state added.")
+	divider := flag.Uint64("timer-divider", 0, "Step the timers once every n instructions instead of once per frame.")
+	platform := flag.String("platform", "chip8", "Instruction set the program was written for: chip8, schip or xochip. Known ROMs default to their recommended setting.")
+	quirks := flag.String("quirks", "", "Compatibility quirks preset: vip, chip48 or schip. Defaults to none, or the recommended preset for known ROMs.")
+	palette := flag.String("palette", "", "Colour palette: "+strings.Join(chip8.PaletteNames(), ", ")+". Press F6 to switch while running.")
+	filter := flag.String("filter", chip8.FilterNone, "Flicker filter: "+strings.Join(chip8.FilterNames(), ", ")+".")
+	strength := flag.Int("filter-strength", chip8.DefaultFilterStrength, "Number of frames the flicker filter keeps a pixel on screen after it's switched off.")
+	dump := flag.String("dump-frames", "", "Directory to write frames to as PNGs.")
+	dumpEvery := flag.Uint64("dump-every", 1, "Only write every nth frame with -dump-frames.")
+	record := flag.String("record", "", "Record the screen to a GIF from the start. Press F8 to start and stop recording while running.")
+	frames := flag.Int("frames", 0, "Stop recording after n frames with -record, zero records until the emulator is closed.")
+	movie := flag.String("movie", "", "Record the keys pressed to a movie file, to replay the run later with -replay.")
+	replay := flag.String("replay", "", "Replay a movie recorded with -movie, checking the run ends the same way.")
+	seed := flag.Int64("seed", 0, "Seed for the random numbers, the same seed gives the same numbers every run. Defaults to the time.")
+	rewind := flag.Int("rewind", chip8.DefaultRewindFrames, "Number of frames kept for rewinding with Backspace, zero turns rewinding off. Always off with -movie and -replay.")
+	config := flag.String("config", "", "JSON config file, defaults to chip8/config.json in the user config directory.")
+	flag.Parse()
+
+	ProgramData, err := GetFile(*program)
+	if err != nil {
+		panic(err)
+	}
+
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	//Settings come from the config, then the ROM database for known ROMs, then the command line.
+	cfg, err := loadConfig(*config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+		os.Exit(1)
+	}
+	if !set["ips"] && cfg.IPS != 0 {
+		*ips = cfg.IPS
+	}
+	if !set["filter"] && cfg.Filter != "" {
+		*filter = cfg.Filter
+	}
+	if !set["filter-strength"] && cfg.FilterStrength != 0 {
+		*strength = cfg.FilterStrength
+	}
+	q := cfg.ApplyQuirks(chip8.Quirks{})
+
+	if rom, ok := romdb.Lookup(ProgramData); ok {
+		fmt.Printf("Detected %s\n", rom.Title)
+		for _, hint := range rom.KeyHints() {
+			fmt.Printf("  %s\n", hint)
+		}
+		if !set["platform"] {
+			*platform = rom.Platform
+		}
+		if !set["ips"] && rom.IPS != 0 {
+			*ips = rom.IPS
+		}
+		if !set["quirks"] {
+			*quirks = rom.Quirks
+		}
+	}
+
+	p, err := chip8.ParsePlatform(*platform)
+	if err != nil {
+		panic(err)
+	}
+	if *quirks != "" {
+		q, err = chip8.LookupQuirks(*quirks)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	m := chip8.Memory{}
+	g := chip8.NewGraphics(&m)
+	in := chip8.NewInput()
+	//Run steps the timers once per frame, or every -timer-divider instructions.
+	dt := chip8.NewManualTimer()
+	st := chip8.NewManualTimer()
+	b := chip8.NewSDLBeeper()
+	err = in.SetKeymap(cfg.Keys())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+		os.Exit(1)
+	}
+	err = configureGraphics(g, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+		os.Exit(1)
+	}
+	if *palette != "" {
+		err = g.SetPalette(*palette)
+		if err != nil {
+			panic(err)
+		}
+	}
+	f, err := chip8.NewFilter(*filter, *strength)
+	if err != nil {
+		panic(err)
+	}
+	g.SetFilter(f)
+	c := chip8.NewCPU(&m, g, in, dt, st, b, q)
+	c.IPS = *ips
+	c.TimerDivider = *divider
+	c.SetPlatform(p)
+	//Movies can't record going back in time, so there's no rewinding while one records or replays.
+	if *rewind > 0 && *movie == "" && *replay == "" {
+		c.Rewind = chip8.NewRewind(*rewind)
+	}
+	if !set["seed"] {
+		*seed = time.Now().UnixNano()
+	}
+	c.Seed(*seed)
+	c.StatePath = *state
+	if c.StatePath == "" {
+		c.StatePath = *program + ".state"
+	}
+	c.ScreenshotPath = strings.TrimSuffix(*program, filepath.Ext(*program))
+	if *dump != "" {
+		err = os.MkdirAll(*dump, 0755)
+		if err != nil {
+			panic(err)
+		}
+		d := chip8.NewFrameDumper(*dump, *dumpEvery)
+		d.Palette = g.Palette()
+		d.Filter, err = chip8.NewFilter(*filter, *strength)
+		if err != nil {
+			panic(err)
+		}
+		c.Taps = append(c.Taps, d)
+	}
+	if *record != "" {
+		c.Recorder = chip8.NewRecorder(g.Palette())
+		c.Recorder.Limit = *frames
+		err = c.Recorder.Start(*record)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not start recording: %v\n", err)
+			os.Exit(1)
+		}
+		c.Taps = append(c.Taps, c.Recorder)
+	}
+
+	err = g.Init()
+	if err != nil {
+		panic(err)
+	}
+	defer g.Destroy()
+
+	//Key names can only be looked up once SDL is initialised.
+	err = in.Init()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+		os.Exit(1)
+	}
+
+	err = b.Init()
+	if err != nil {
+		panic(err)
+	}
+	defer b.Destroy()
+
+	err = c.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	err = c.LoadProgram(ProgramData)
+	if err != nil {
+		panic(err)
+	}
+
+	if *debug {
+		err = chip8.NewDebugger(c, os.Stdin, os.Stdout).Run()
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	var mov *chip8.Movie
+	switch {
+	case *replay != "":
+		mov, err = chip8.LoadMovie(*replay)
+		if err != nil {
+			panic(err)
+		}
+		err = c.PlayMovie(mov, ProgramData)
+		if err != nil {
+			panic(err)
+		}
+	case *movie != "":
+		mov = c.StartMovie(ProgramData, *seed)
+	}
+
+	err = c.Run()
+	if c.Recorder != nil && c.Recorder.Recording() {
+		if err := c.Recorder.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "could not save recording: %v\n", err)
+		}
+	}
+	if err != nil {
+		c.Panic(err)
+	}
+
+	switch {
+	case *replay != "":
+		err = c.CheckMovie(mov)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "replay does not match: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Replay matches the recording")
+	case *movie != "":
+		err = c.EndMovie(mov)
+		if err == nil {
+			err = mov.SaveFile(*movie)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not save movie: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Saved movie to %s\n", *movie)
+	}
+}
+
+//configureGraphics applies the config's scale, palette and colours to the window.
+func configureGraphics(g *chip8.Graphics, cfg *chip8.Config) error {
+	if cfg.Scale != 0 {
+		err := g.SetScale(int32(cfg.Scale))
+		if err != nil {
+			return &chip8.ConfigError{Field: "scale", Msg: err.Error()}
+		}
+	}
+	if cfg.Palette != "" {
+		err := g.SetPalette(cfg.Palette)
+		if err != nil {
+			return &chip8.ConfigError{Field: "palette", Msg: err.Error()}
+		}
+	}
+	if cfg.Foreground == "" && cfg.Background == "" {
+		return nil
+	}
+	colours := g.Palette()
+	fg, bg := color.Color(colours[1]), color.Color(colours[0])
+	var err error
+	if cfg.Foreground != "" {
+		fg, err = chip8.ParseColour(cfg.Foreground)
+		if err != nil {
+			return &chip8.ConfigError{Field: "foreground", Msg: err.Error()}
+		}
+	}
+	if cfg.Background != "" {
+		bg, err = chip8.ParseColour(cfg.Background)
+		if err != nil {
+			return &chip8.ConfigError{Field: "background", Msg: err.Error()}
+		}
+	}
+	g.SetColours(fg, bg)
+	return nil
+}
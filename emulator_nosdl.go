@@ -0,0 +1,22 @@
+//go:build nosdl
+// +build nosdl
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+//runEmulator can't open a window without SDL, builds with the nosdl tag only have the subcommands.
+func runEmulator() {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "chip8 was built without SDL, only these commands are available: %s\n", strings.Join(names, ", "))
+	os.Exit(2)
+}
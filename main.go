@@ -1,11 +1,8 @@
 package main
 
 import (
-	"flag"
 	"fmt"
 	"os"
-
-	chip8 "github.com/Neffats/Chip8/src"
 )
 
 func GetFile(filename string) ([]byte, error) {
@@ -33,32 +30,24 @@ func GetFile(filename string) ([]byte, error) {
 	return buffer, nil
 }
 
-func main() {
-	program := flag.String("p", "", "Chip8 program file.")
-	flag.Parse()
-
-	ProgramData, err := GetFile(*program)
-
-	m := chip8.Memory{}
-	g := chip8.NewGraphics(&m)
-	in := chip8.NewInput()
-	dt := chip8.NewTimer()
-	in.Init()
-	c := chip8.NewCPU(&m, g, in, dt)
-
-	err = g.Init()
-	if err != nil {
-		panic(err)
-	}
-	defer g.Destroy()
+//commands are the subcommands, run as chip8 <command> [args].
+var commands = map[string]func(args []string) error{
+	"disasm":       disasmCommand,
+	"asm":          asmCommand,
+	"run-headless": runHeadlessCommand,
+}
 
-	err = c.LoadProgram(ProgramData)
-	if err != nil {
-		panic(err)
+func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			err := cmd(os.Args[2:])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
 	}
 
-	err = c.Run()
-	if err != nil {
-		c.Panic(err)
-	}
+	runEmulator()
 }
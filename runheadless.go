@@ -0,0 +1,167 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	chip8 "github.com/Neffats/Chip8/src"
+	"github.com/Neffats/Chip8/src/romdb"
+)
+
+//runHeadlessCommand runs a program without a window and prints the registers at the end,
+//usage: chip8 run-headless -p rom.ch8 [-cycles n] [-keys script.txt] [-o screen.png]
+func runHeadlessCommand(args []string) error {
+	fs := flag.NewFlagSet("run-headless", flag.ExitOnError)
+	program := fs.String("p", "", "Chip8 program file.")
+	cycles := fs.Uint64("cycles", 100000, "Number of instructions to run, stops sooner if the program exits.")
+	keys := fs.String("keys", "", "Key script, one \"<cycle> press|release <key>\" per line.")
+	out := fs.String("o", "", "Write the screen at the end to a .png, .pbm or .txt file.")
+	ips := fs.Int("ips", chip8.DefaultIPS, "Instructions per second, sets how often the timers are stepped. Known ROMs default to their recommended speed.")
+	platform := fs.String("platform", "chip8", "Instruction set the program was written for: chip8, schip or xochip. Known ROMs default to their recommended setting.")
+	quirks := fs.String("quirks", "", "Compatibility quirks preset: vip, chip48 or schip. Defaults to none, or the recommended preset for known ROMs.")
+	seed := fs.Int64("seed", 0, "Seed for the random numbers.")
+	record := fs.String("record", "", "Record the screen to a GIF.")
+	frames := fs.Int("frames", 0, "Stop recording after n frames with -record, zero records the whole run.")
+	filter := fs.String("filter", chip8.FilterNone, "Flicker filter for -o and -dump-frames: "+strings.Join(chip8.FilterNames(), ", ")+". Only .png output can be filtered.")
+	strength := fs.Int("filter-strength", chip8.DefaultFilterStrength, "Number of frames the flicker filter keeps a pixel on screen after it's switched off.")
+	dump := fs.String("dump-frames", "", "Directory to write frames to as PNGs.")
+	dumpEvery := fs.Uint64("dump-every", 1, "Only write every nth frame with -dump-frames.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: chip8 run-headless -p rom.ch8 [-cycles n] [-keys script.txt] [-o screen.png]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *program == "" || fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	data, err := GetFile(*program)
+	if err != nil {
+		return err
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if rom, ok := romdb.Lookup(data); ok {
+		if !set["platform"] {
+			*platform = rom.Platform
+		}
+		if !set["ips"] && rom.IPS != 0 {
+			*ips = rom.IPS
+		}
+		if !set["quirks"] {
+			*quirks = rom.Quirks
+		}
+	}
+
+	p, err := chip8.ParsePlatform(*platform)
+	if err != nil {
+		return err
+	}
+	var q chip8.Quirks
+	if *quirks != "" {
+		q, err = chip8.LookupQuirks(*quirks)
+		if err != nil {
+			return err
+		}
+	}
+
+	//Each use of the filter needs its own, they remember the frames before.
+	newFilter := func() (chip8.Filter, error) {
+		return chip8.NewFilter(*filter, *strength)
+	}
+	screenFilter, err := newFilter()
+	if err != nil {
+		return err
+	}
+	if screenFilter != nil && *out != "" && strings.ToLower(filepath.Ext(*out)) != ".png" {
+		return fmt.Errorf("-filter only applies to .png output, got %s", *out)
+	}
+
+	c := chip8.NewHeadlessCPU(q)
+	c.IPS = *ips
+	c.SetPlatform(p)
+	c.Seed(*seed)
+	if *keys != "" {
+		f, err := os.Open(*keys)
+		if err != nil {
+			return fmt.Errorf("could not open key script: %v", err)
+		}
+		events, err := chip8.ParseKeyScript(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", *keys, err)
+		}
+		keypad := c.Input.(*chip8.VirtualKeypad)
+		for _, e := range events {
+			err = keypad.Schedule(e.Cycle, e.Key, e.Pressed)
+			if err != nil {
+				return fmt.Errorf("%s: %v", *keys, err)
+			}
+		}
+	}
+	palette, _ := chip8.LookupPalette(chip8.DefaultPalette)
+	if *record != "" {
+		c.Recorder = chip8.NewRecorder(palette)
+		c.Recorder.Limit = *frames
+		err = c.Recorder.Start(*record)
+		if err != nil {
+			return err
+		}
+		c.Taps = append(c.Taps, c.Recorder)
+	}
+	if *dump != "" {
+		err = os.MkdirAll(*dump, 0755)
+		if err != nil {
+			return fmt.Errorf("could not create frame dump directory: %v", err)
+		}
+		d := chip8.NewFrameDumper(*dump, *dumpEvery)
+		d.Palette = palette
+		d.Filter, err = newFilter()
+		if err != nil {
+			return err
+		}
+		c.Taps = append(c.Taps, d)
+	}
+	var screen *chip8.FilterTap
+	if screenFilter != nil && *out != "" {
+		screen = &chip8.FilterTap{Filter: screenFilter, Palette: palette}
+		c.Taps = append(c.Taps, screen)
+	}
+
+	err = c.Init()
+	if err != nil {
+		return err
+	}
+	err = c.LoadProgram(data)
+	if err != nil {
+		return err
+	}
+
+	runErr := c.RunUntil(*cycles)
+	c.WriteRegisters(os.Stdout)
+	if c.Halted {
+		fmt.Println("Program exited")
+	}
+
+	if c.Recorder != nil && c.Recorder.Recording() {
+		err = c.Recorder.Stop()
+		if err != nil {
+			return fmt.Errorf("could not save recording: %v", err)
+		}
+	}
+	switch {
+	case screen != nil && screen.Image != nil:
+		err = chip8.SavePNG(*out, screen.Image)
+	case *out != "":
+		err = chip8.SaveScreenshot(*out, c.G.Frame(), palette)
+	}
+	if err != nil {
+		return err
+	}
+	return runErr
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	chip8 "github.com/Neffats/Chip8/src"
+)
+
+//writeROM writes the program to a file for the commands to read.
+func writeROM(t *testing.T, program []byte) string {
+	name := filepath.Join(t.TempDir(), "rom.ch8")
+	err := ioutil.WriteFile(name, program, 0644)
+	if err != nil {
+		t.Fatalf("failed to write rom: %v", err)
+	}
+	return name
+}
+
+func TestRunHeadlessDumpFrames(t *testing.T) {
+	//LD I, 0; DRW V0, V0, 5; JP 0x204
+	rom := writeROM(t, []byte{0xA0, 0x00, 0xD0, 0x05, 0x12, 0x04})
+	dir := filepath.Join(t.TempDir(), "frames")
+
+	//10 instructions a frame at 600 IPS, so 10 frames with every other one written.
+	err := runHeadlessCommand([]string{"-p", rom, "-cycles", "100", "-ips", "600", "-dump-frames", dir, "-dump-every", "2"})
+	if err != nil {
+		t.Fatalf("run-headless failed: %v", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.png"))
+	if err != nil {
+		t.Fatalf("failed to list frames: %v", err)
+	}
+	if len(files) != 5 {
+		t.Errorf("expected 5 frames; got: %v", files)
+	}
+}
+
+//The flicker filter applies to the -o screenshot, which only works for PNGs.
+func TestRunHeadlessFilter(t *testing.T) {
+	//LD I, 0; DRW V0, V0, 5; LD V1, 0 x8; CLS; JP 0x216
+	program := []byte{0xA0, 0x00, 0xD0, 0x05}
+	for i := 0; i < 8; i++ {
+		program = append(program, 0x61, 0x00)
+	}
+	program = append(program, 0x00, 0xE0, 0x12, 0x16)
+	rom := writeROM(t, program)
+	out := filepath.Join(t.TempDir(), "screen.png")
+
+	//The 0 sprite is on screen at the end of the first frame and cleared in the second,
+	//the decay filter still shows it faded.
+	err := runHeadlessCommand([]string{"-p", rom, "-cycles", "20", "-ips", "600", "-filter", "decay", "-o", out})
+	if err != nil {
+		t.Fatalf("run-headless failed: %v", err)
+	}
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatalf("failed to open screenshot: %v", err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("failed to decode screenshot: %v", err)
+	}
+	palette, _ := chip8.LookupPalette(chip8.DefaultPalette)
+	r, g, b, _ := img.At(0, 0).RGBA()
+	br, bg, bb, _ := palette[0].RGBA()
+	if r == br && g == bg && b == bb {
+		t.Errorf("expected the cleared sprite to still show with the filter")
+	}
+
+	err = runHeadlessCommand([]string{"-p", rom, "-cycles", "20", "-filter", "or", "-o", filepath.Join(t.TempDir(), "screen.txt")})
+	if err == nil {
+		t.Errorf("expected an error filtering text output")
+	}
+}
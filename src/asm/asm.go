@@ -0,0 +1,363 @@
+//Package asm assembles Cowgod style CHIP-8 assembly into programs the CPU can load.
+//
+//Each line holds an optional label, then an instruction or directive, then an optional comment:
+//
+//	loop:   LD V0, 0x20     ; instructions use the mnemonics from the opcode table
+//	        JP loop
+//	WIDTH   equ 8           ; constants
+//	sprite: db 0xF0, 0x90   ; raw data
+//	        LD I, long sprite ; XO-CHIP 16 bit address, takes 4 bytes
+//
+//Numbers can be decimal, hex (0x1F, $1F, #1F) or binary (0b1010, %1010).
+package asm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	chip8 "github.com/Neffats/Chip8/src"
+)
+
+//Error is an assembly error on a given line.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+//ErrorList holds every error found in a source file.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+//statement is a parsed instruction or db directive.
+type statement struct {
+	line     int
+	addr     uint16
+	mnemonic string
+	operands []string
+	//size is the number of bytes the statement assembles to.
+	size int
+}
+
+type assembler struct {
+	symbols    map[string]uint16
+	statements []statement
+	errs       ErrorList
+}
+
+//Assemble reads assembly source and returns the program, starting at chip8.PCInit.
+//The error is an ErrorList if there were problems with the source.
+func Assemble(src io.Reader) ([]byte, error) {
+	a := &assembler{symbols: make(map[string]uint16)}
+
+	err := a.parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("could not read source: %v", err)
+	}
+	program := a.encode()
+
+	if len(a.errs) > 0 {
+		//Symbol errors are only found after the whole source is read, put them back in line order.
+		sort.SliceStable(a.errs, func(i, j int) bool { return a.errs[i].Line < a.errs[j].Line })
+		return nil, a.errs
+	}
+	return program, nil
+}
+
+func (a *assembler) errorf(line int, format string, args ...interface{}) {
+	a.errs = append(a.errs, &Error{Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+//parse is the first pass, it works out the address of every statement and collects the symbols.
+func (a *assembler) parse(src io.Reader) error {
+	addr := chip8.PCInit
+	scanner := bufio.NewScanner(src)
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		if i := strings.Index(text, ";"); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+
+		//Labels.
+		if i := strings.Index(text, ":"); i >= 0 {
+			a.define(n, strings.TrimSpace(text[:i]), addr)
+			text = strings.TrimSpace(text[i+1:])
+		}
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Fields(text)
+
+		//Constants.
+		if len(fields) >= 3 && strings.EqualFold(fields[1], "equ") {
+			v, err := a.value(strings.Join(fields[2:], ""))
+			if err != nil {
+				a.errorf(n, "%v", err)
+				continue
+			}
+			a.define(n, fields[0], v)
+			continue
+		}
+
+		s := statement{line: n, addr: addr, mnemonic: strings.ToUpper(fields[0])}
+		rest := strings.TrimSpace(text[len(fields[0]):])
+		if rest != "" {
+			for _, op := range strings.Split(rest, ",") {
+				s.operands = append(s.operands, strings.TrimSpace(op))
+			}
+		}
+
+		if s.mnemonic == "DB" {
+			if len(s.operands) == 0 {
+				a.errorf(n, "db needs at least one byte")
+				continue
+			}
+			s.size = len(s.operands)
+		} else {
+			s.size = 2
+			for _, op := range s.operands {
+				if _, ok := long(op); ok {
+					s.size = 4
+				}
+			}
+		}
+		addr += uint16(s.size)
+		a.statements = append(a.statements, s)
+	}
+	return scanner.Err()
+}
+
+func (a *assembler) define(line int, name string, v uint16) {
+	if !validSymbol(name) {
+		a.errorf(line, "invalid symbol name %q", name)
+		return
+	}
+	key := strings.ToUpper(name)
+	if _, exists := a.symbols[key]; exists {
+		a.errorf(line, "%q is already defined", name)
+		return
+	}
+	a.symbols[key] = v
+}
+
+//encode is the second pass, it turns each statement into bytes now every symbol is known.
+func (a *assembler) encode() []byte {
+	var program []byte
+	for _, s := range a.statements {
+		if s.mnemonic == "DB" {
+			for _, op := range s.operands {
+				v, err := a.value(op)
+				if err != nil {
+					a.errorf(s.line, "%v", err)
+				} else if v > 0xFF {
+					a.errorf(s.line, "db value %s does not fit in a byte", op)
+				}
+				program = append(program, byte(v))
+			}
+			continue
+		}
+
+		inst, err := a.instruction(s)
+		if err != nil {
+			a.errorf(s.line, "%v", err)
+			//Keep the following addresses where the first pass put them.
+			inst = make([]byte, s.size)
+		}
+		program = append(program, inst...)
+	}
+	return program
+}
+
+//instruction finds the opcode that matches the statement's mnemonic and operands and encodes it.
+func (a *assembler) instruction(s statement) ([]byte, error) {
+	operands := s.operands
+	//SHR Vx and SHL Vx shift Vx whichever way the shift quirk is set.
+	if (s.mnemonic == "SHR" || s.mnemonic == "SHL") && len(operands) == 1 {
+		operands = []string{operands[0], operands[0]}
+	}
+
+	known := false
+	var lastErr error
+	for _, op := range chip8.Opcodes() {
+		if op.Mnemonic != s.mnemonic {
+			continue
+		}
+		known = true
+		if len(op.Operands) != len(operands) {
+			continue
+		}
+		inst, ok, err := a.match(op, operands)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if ok {
+			return inst, nil
+		}
+	}
+
+	if !known {
+		return nil, fmt.Errorf("unknown instruction %q", s.mnemonic)
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("invalid operands for %s: %s", s.mnemonic, strings.Join(s.operands, ", "))
+}
+
+//match tries to fit the operands to an opcode's operand layout.
+//Returns false if they're the wrong kinds, and an error if the kinds fit but a value doesn't.
+func (a *assembler) match(op chip8.Opcode, operands []string) ([]byte, bool, error) {
+	inst := op.Pattern
+	var extra []byte
+	for i, want := range op.Operands {
+		got := operands[i]
+		reg, isReg := register(got)
+		addr, isLong := long(got)
+
+		switch want {
+		case "Vx", "Vy":
+			if !isReg {
+				return nil, false, nil
+			}
+			if want == "Vx" {
+				inst |= uint16(reg) << 8
+			} else {
+				inst |= uint16(reg) << 4
+			}
+		case "nnn", "kk", "n", "x":
+			if isReg || isLong || reserved(got) {
+				return nil, false, nil
+			}
+			v, err := a.value(got)
+			if err != nil {
+				return nil, false, err
+			}
+			limit := map[string]uint16{"nnn": 0xFFF, "kk": 0xFF, "n": 0xF, "x": 0xF}[want]
+			if v > limit {
+				return nil, false, fmt.Errorf("%s does not fit in %s, max 0x%X", got, want, limit)
+			}
+			if want == "x" {
+				v <<= 8
+			}
+			inst |= v
+		case "long":
+			if !isLong {
+				return nil, false, nil
+			}
+			v, err := a.value(addr)
+			if err != nil {
+				return nil, false, err
+			}
+			extra = []byte{byte(v >> 8), byte(v)}
+		default:
+			if !strings.EqualFold(got, want) {
+				return nil, false, nil
+			}
+		}
+	}
+	return append([]byte{byte(inst >> 8), byte(inst)}, extra...), true, nil
+}
+
+//long returns the address part of a "long 0x1234" operand.
+func long(s string) (string, bool) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "long") {
+		return "", false
+	}
+	return fields[1], true
+}
+
+//value resolves a number or symbol.
+func (a *assembler) value(s string) (uint16, error) {
+	if v, ok := a.symbols[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	if validSymbol(s) {
+		return 0, fmt.Errorf("undefined symbol %q", s)
+	}
+
+	base := 10
+	digits := s
+	switch {
+	case strings.HasPrefix(s, "0x"), strings.HasPrefix(s, "0X"):
+		base, digits = 16, s[2:]
+	case strings.HasPrefix(s, "$"), strings.HasPrefix(s, "#"):
+		base, digits = 16, s[1:]
+	case strings.HasPrefix(s, "0b"), strings.HasPrefix(s, "0B"):
+		base, digits = 2, s[2:]
+	case strings.HasPrefix(s, "%"):
+		base, digits = 2, s[1:]
+	}
+	v, err := strconv.ParseUint(digits, base, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return uint16(v), nil
+}
+
+//register returns the register number if s is V0 to VF.
+func register(s string) (uint8, bool) {
+	if len(s) != 2 || (s[0] != 'V' && s[0] != 'v') {
+		return 0, false
+	}
+	r, err := strconv.ParseUint(s[1:], 16, 8)
+	if err != nil {
+		return 0, false
+	}
+	return uint8(r), true
+}
+
+//literals are the operands written literally in the opcode table, like "I" and "DT".
+var literals = func() map[string]bool {
+	l := make(map[string]bool)
+	for _, op := range chip8.Opcodes() {
+		for _, operand := range op.Operands {
+			switch operand {
+			case "Vx", "Vy", "nnn", "kk", "n", "x", "long":
+			default:
+				l[strings.ToUpper(operand)] = true
+			}
+		}
+	}
+	return l
+}()
+
+//reserved returns true for the literal operands, which can't be used as symbols.
+func reserved(s string) bool {
+	return literals[strings.ToUpper(s)]
+}
+
+func validSymbol(s string) bool {
+	if s == "" || reserved(s) {
+		return false
+	}
+	if _, isReg := register(s); isReg {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
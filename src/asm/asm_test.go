@@ -0,0 +1,107 @@
+package asm
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/Neffats/Chip8/src/disasm"
+)
+
+func TestAssemble(t *testing.T) {
+	tt := []struct {
+		name     string
+		src      string
+		expected []byte
+	}{
+		{name: "Instructions", src: "CLS\nLD V1, 0x20\nDRW V0, V1, 5\nRET",
+			expected: []byte{0x00, 0xE0, 0x61, 0x20, 0xD0, 0x15, 0x00, 0xEE}},
+		{name: "Case and spacing", src: "  ld   va ,  vB\n\tse V3,#0A  ; comment\n",
+			expected: []byte{0x8A, 0xB0, 0x33, 0x0A}},
+		{name: "Literal operands", src: "LD I, 0x300\nLD [I], V5\nLD V5, [I]\nLD V2, K\nLD DT, V2\nLD ST, V2\nLD F, V2\nLD B, V2\nADD I, V2\nJP V0, 0x210",
+			expected: []byte{0xA3, 0x00, 0xF5, 0x55, 0xF5, 0x65, 0xF2, 0x0A, 0xF2, 0x15, 0xF2, 0x18, 0xF2, 0x29, 0xF2, 0x33, 0xF2, 0x1E, 0xB2, 0x10}},
+		{name: "Labels", src: "start: JP end\nloop:\n  CALL loop\nend: JP start",
+			expected: []byte{0x12, 0x04, 0x22, 0x02, 0x12, 0x00}},
+		{name: "Constants and data", src: "X equ 10\nCOUNT EQU %101\nLD V0, X\nLD V1, COUNT\nLD I, sprite\nsprite: db 0xF0, $90, 0b11110000, 255",
+			expected: []byte{0x60, 0x0A, 0x61, 0x05, 0xA2, 0x06, 0xF0, 0x90, 0xF0, 0xFF}},
+		{name: "Single operand shifts", src: "SHR V4\nSHL V4, V6",
+			expected: []byte{0x84, 0x46, 0x84, 0x6E}},
+		{name: "Super and XO instructions", src: "HIGH\nSCD 4\nLD HF, V2\nPLANE 3\nSAVE V1, V4\nLD I, long data\ndata: db 1",
+			expected: []byte{0x00, 0xFF, 0x00, 0xC4, 0xF2, 0x30, 0xF3, 0x01, 0x51, 0x42, 0xF0, 0x00, 0x02, 0x0E, 0x01}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Assemble(strings.NewReader(tc.src))
+			if err != nil {
+				t.Fatalf("failed to assemble: %v", err)
+			}
+			if !bytes.Equal(got, tc.expected) {
+				t.Errorf("expected: % X; got: % X", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tt := []struct {
+		name     string
+		src      string
+		expected []string
+	}{
+		{name: "Unknown instruction", src: "CLS\nFOO V1", expected: []string{`line 2: unknown instruction "FOO"`}},
+		{name: "Undefined label", src: "JP nowhere", expected: []string{`line 1: undefined symbol "nowhere"`}},
+		{name: "Value too big", src: "LD V0, 0x100\nDRW V0, V1, 16", expected: []string{"line 1: 0x100 does not fit in kk", "line 2: 16 does not fit in n"}},
+		{name: "Bad operands", src: "\n\nLD DT, 5", expected: []string{"line 3: invalid operands for LD: DT, 5"}},
+		{name: "Duplicate label", src: "a: CLS\na: RET", expected: []string{`line 2: "a" is already defined`}},
+		{name: "Bad number", src: "db 0xZZ", expected: []string{`line 1: invalid number "0xZZ"`}},
+		{name: "Sorted by line", src: "JP nowhere\nFOO V1", expected: []string{`line 1: undefined symbol "nowhere"`, `line 2: unknown instruction "FOO"`}},
+		{name: "Long operand on short instruction", src: "JP long 0x1234", expected: []string{"line 1: invalid operands for JP: long 0x1234"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Assemble(strings.NewReader(tc.src))
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			errs, ok := err.(ErrorList)
+			if !ok {
+				t.Fatalf("expected an ErrorList; got: %v", err)
+			}
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("expected %d errors; got: %v", len(tc.expected), errs)
+			}
+			for i, e := range errs {
+				if !strings.HasPrefix(e.Error(), tc.expected[i]) {
+					t.Errorf("expected: %q; got: %q", tc.expected[i], e.Error())
+				}
+			}
+		})
+	}
+}
+
+//Disassembling a ROM and assembling the listing should give back the same bytes.
+func TestRoundTrip(t *testing.T) {
+	roms := []string{"IBM.ch8", "BREAKOUT.ch8", "Airplane.ch8", "waitforkey.ch8"}
+	for _, rom := range roms {
+		t.Run(rom, func(t *testing.T) {
+			program, err := ioutil.ReadFile("../../examples/" + rom)
+			if err != nil {
+				t.Fatalf("failed to read rom: %v", err)
+			}
+			var src strings.Builder
+			for _, l := range disasm.Disassemble(program, 0x200) {
+				src.WriteString(l.Text + "\n")
+			}
+			got, err := Assemble(strings.NewReader(src.String()))
+			if err != nil {
+				t.Fatalf("failed to assemble listing: %v", err)
+			}
+			if !bytes.Equal(got, program) {
+				t.Errorf("round trip changed the program")
+			}
+		})
+	}
+}
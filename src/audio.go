@@ -0,0 +1,109 @@
+//go:build !nosdl
+// +build !nosdl
+
+package chip8
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	//AudioFrequency is the sample rate of the SDL audio device.
+	AudioFrequency = 44100
+	//ToneFrequency is the pitch of the beep in Hz.
+	ToneFrequency = 440
+
+	//toneLength is how many seconds of tone get queued per beep.
+	//The sound timer can't run for longer than 255/60 seconds.
+	toneLength = 5
+)
+
+//SDLBeeper plays a square wave tone through an SDL audio device.
+type SDLBeeper struct {
+	device  sdl.AudioDeviceID
+	tone    []byte
+	playing bool
+}
+
+//NewSDLBeeper returns a new beeper, Init must be called before it can be used.
+func NewSDLBeeper() *SDLBeeper {
+	return &SDLBeeper{}
+}
+
+//Init opens the audio device and generates the tone, SDL must already be initialised.
+func (b *SDLBeeper) Init() error {
+	spec := sdl.AudioSpec{
+		Freq:     AudioFrequency,
+		Format:   sdl.AUDIO_U8,
+		Channels: 1,
+		Samples:  1024,
+	}
+
+	var err error
+	b.device, err = sdl.OpenAudioDevice("", false, &spec, nil, 0)
+	if err != nil {
+		return fmt.Errorf("could not open audio device: %v", err)
+	}
+
+	//Square wave centred on the unsigned 8 bit silence value.
+	period := AudioFrequency / ToneFrequency
+	b.tone = make([]byte, AudioFrequency*toneLength)
+	for i := range b.tone {
+		if (i % period) < period/2 {
+			b.tone[i] = 128 + 32
+		} else {
+			b.tone[i] = 128 - 32
+		}
+	}
+
+	return nil
+}
+
+//Start queues the tone and unpauses the device.
+func (b *SDLBeeper) Start(cycle uint64) error {
+	sdl.ClearQueuedAudio(b.device)
+	err := sdl.QueueAudio(b.device, b.tone)
+	if err != nil {
+		return fmt.Errorf("could not queue tone: %v", err)
+	}
+	sdl.PauseAudioDevice(b.device, false)
+	b.playing = true
+	return nil
+}
+
+//Stop pauses the device and drops whatever is left of the tone.
+func (b *SDLBeeper) Stop(cycle uint64) error {
+	sdl.PauseAudioDevice(b.device, true)
+	sdl.ClearQueuedAudio(b.device)
+	b.playing = false
+	return nil
+}
+
+//SetPattern replaces the tone with the 128 bit pattern looped, one bit per sample
+//at 4000*2^((pitch-64)/48) samples per second. A beep already playing switches straight over.
+func (b *SDLBeeper) SetPattern(pattern [16]byte, pitch uint8) error {
+	rate := 4000 * math.Pow(2, (float64(pitch)-DefaultPitch)/48)
+
+	b.tone = make([]byte, AudioFrequency*toneLength)
+	for i := range b.tone {
+		bit := int(float64(i)*rate/AudioFrequency) % 128
+		if pattern[bit/8]&(0x80>>uint(bit%8)) != 0 {
+			b.tone[i] = 128 + 32
+		} else {
+			b.tone[i] = 128 - 32
+		}
+	}
+
+	if b.playing {
+		return b.Start(0)
+	}
+	return nil
+}
+
+//Destroy closes the audio device.
+func (b *SDLBeeper) Destroy() {
+	sdl.CloseAudioDevice(b.device)
+}
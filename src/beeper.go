@@ -0,0 +1,82 @@
+package chip8
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+//DefaultPitch is the XO-CHIP pitch register's starting value, plays patterns at 4000 bits per second.
+const DefaultPitch = 64
+
+//BeepEvent records the cycle a beep started or stopped on.
+type BeepEvent struct {
+	Cycle uint64
+	On    bool
+}
+
+//RecordingBeeper makes no sound, it keeps a list of when the beeper would have started and stopped.
+//Used for headless runs and tests.
+type RecordingBeeper struct {
+	Events []BeepEvent
+
+	//Pattern and Pitch are the last audio pattern set, if any.
+	Pattern [16]byte
+	Pitch   uint8
+
+	//Log will also have each event written to it when set.
+	Log io.Writer
+
+	mux sync.Mutex
+}
+
+//NewRecordingBeeper returns a beeper with no events recorded.
+func NewRecordingBeeper() *RecordingBeeper {
+	return &RecordingBeeper{}
+}
+
+//Start records the beep starting.
+func (b *RecordingBeeper) Start(cycle uint64) error {
+	return b.record(cycle, true)
+}
+
+//Stop records the beep stopping.
+func (b *RecordingBeeper) Stop(cycle uint64) error {
+	return b.record(cycle, false)
+}
+
+//SetPattern records the audio pattern.
+func (b *RecordingBeeper) SetPattern(pattern [16]byte, pitch uint8) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.Pattern = pattern
+	b.Pitch = pitch
+
+	if b.Log == nil {
+		return nil
+	}
+	_, err := fmt.Fprintf(b.Log, "audio pattern: % X, pitch %d\n", pattern, pitch)
+	if err != nil {
+		return fmt.Errorf("could not write audio pattern to log: %v", err)
+	}
+	return nil
+}
+
+func (b *RecordingBeeper) record(cycle uint64, on bool) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.Events = append(b.Events, BeepEvent{Cycle: cycle, On: on})
+
+	if b.Log == nil {
+		return nil
+	}
+	state := "stop"
+	if on {
+		state = "start"
+	}
+	_, err := fmt.Fprintf(b.Log, "beep %s: cycle %d\n", state, cycle)
+	if err != nil {
+		return fmt.Errorf("could not write beep to log: %v", err)
+	}
+	return nil
+}
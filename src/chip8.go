@@ -4,3 +4,62 @@ type memory interface {
 	Write(data byte, addr uint16) error
 	Read(addr uint16) (byte, error)
 }
+
+//Display is what the CPU draws to. Graphics renders to an SDL window,
+//Framebuffer keeps the screen in memory only.
+type Display interface {
+	Init() error
+	Draw(x int32, y int32, n uint8, addr uint16) (bool, error)
+	DrawLarge(x int32, y int32, addr uint16) (bool, error)
+	Scroll(dx int32, dy int32) error
+	HighRes() bool
+	SetHighRes(on bool) error
+	Planes() uint8
+	SetPlanes(mask uint8) error
+	//SetClip picks between sprites being cut off at the screen edge or wrapping around.
+	SetClip(on bool)
+	ClearScreen() error
+	PaintSurface() error
+	Pixel(x int32, y int32) (uint8, error)
+	Frame() Frame
+	SetFrame(f Frame) error
+	Destroy()
+}
+
+//Frame is a copy of the screen, one byte per pixel in rows starting from the top left.
+//Each pixel holds one bit per plane, so it is 0 or 1 unless an XO-CHIP program draws to plane 2.
+type Frame struct {
+	W, H int
+	Pix  []uint8
+}
+
+//At returns the pixel at (x, y).
+func (f Frame) At(x int, y int) uint8 {
+	return f.Pix[y*f.W+x]
+}
+
+//Keypad is the 16 key hex keypad, keys are numbered 0x0 through 0xF.
+//Input reads an SDL keyboard, VirtualKeypad is driven by code.
+type Keypad interface {
+	Update(cycle uint64) error
+	IsPressed(key uint8) (bool, error)
+	WaitForKey() (uint8, error)
+	//State returns the held keys as a bit mask, bit n set for key n.
+	State() uint16
+	SetState(keys uint16) error
+}
+
+//Beeper plays the CHIP-8's tone while the sound timer is non-zero.
+//Start and Stop are given the CPU cycle the change happened on.
+type Beeper interface {
+	Start(cycle uint64) error
+	Stop(cycle uint64) error
+	//SetPattern replaces the tone with an XO-CHIP 1 bit audio pattern, played at the rate set by pitch.
+	SetPattern(pattern [16]byte, pitch uint8) error
+}
+
+//FrameTap is handed every frame once the CPU has run it, for dumping or recording the screen.
+//n counts the frames run so far, starting from 1.
+type FrameTap interface {
+	TapFrame(n uint64, frame Frame) error
+}
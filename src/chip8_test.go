@@ -7,8 +7,9 @@ type treg struct {
 
 func setup() *CPU {
 	m := Memory{}
-	g := NewGraphics(&m)
-	i := NewInput()
-	dt := NewTimer()
-	return NewCPU(&m, g, i, dt)
+	g := NewFramebuffer(&m)
+	i := NewVirtualKeypad()
+	dt := NewManualTimer()
+	st := NewManualTimer()
+	return NewCPU(&m, g, i, dt, st, NewRecordingBeeper(), Quirks{})
 }
@@ -0,0 +1,278 @@
+package chip8
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//DefaultKeymap is the keyboard layout used when the config doesn't set one.
+//The left hand side of a QWERTY keyboard, laid out like the COSMAC VIP keypad.
+var DefaultKeymap = map[uint8][]string{
+	0x1: {"1"}, 0x2: {"2"}, 0x3: {"3"}, 0xC: {"4"},
+	0x4: {"Q"}, 0x5: {"W"}, 0x6: {"E"}, 0xD: {"R"},
+	0x7: {"A"}, 0x8: {"S"}, 0x9: {"D"}, 0xE: {"F"},
+	0xA: {"\\"}, 0x0: {"Z"}, 0xB: {"X"}, 0xF: {"C"},
+}
+
+//Config holds the user's emulator settings, read from a JSON file.
+//Every field is optional, anything left out keeps its default.
+//
+//	{
+//		"keymap": {"5": ["W", "Up"], "8": ["S", "Down"]},
+//		"scale": 10,
+//		"palette": "amber",
+//		"foreground": "#33FF66",
+//		"background": "#000000",
+//		"filter": "decay",
+//		"filter_strength": 3,
+//		"ips": 700,
+//		"quirks": {"preset": "vip", "clip": false}
+//	}
+type Config struct {
+	//Keymap maps CHIP-8 keys, as hex digits, to the SDL names of the keyboard keys that press them.
+	//Keys that aren't listed keep their default mapping.
+	Keymap map[string][]string `json:"keymap,omitempty"`
+	//Scale is the size of a CHIP-8 pixel in window pixels.
+	Scale int `json:"scale,omitempty"`
+	//Palette is the name of a colour palette, see PaletteNames.
+	Palette string `json:"palette,omitempty"`
+	//Foreground and Background are colours written as #RRGGBB, they replace the palette's first two colours.
+	Foreground string `json:"foreground,omitempty"`
+	Background string `json:"background,omitempty"`
+	//Filter is the name of the flicker filter, see FilterNames, and FilterStrength how many frames it keeps pixels for.
+	Filter         string `json:"filter,omitempty"`
+	FilterStrength int    `json:"filter_strength,omitempty"`
+	//IPS is the clock speed in instructions per second.
+	IPS    int           `json:"ips,omitempty"`
+	Quirks *QuirksConfig `json:"quirks,omitempty"`
+}
+
+//QuirksConfig picks a quirks preset, then turns individual quirks on or off.
+type QuirksConfig struct {
+	Preset                 string `json:"preset,omitempty"`
+	ShiftVy                *bool  `json:"shift_vy,omitempty"`
+	LoadStoreIncrementI    *bool  `json:"load_store_increment_i,omitempty"`
+	LoadStoreIncrementIByX *bool  `json:"load_store_increment_i_by_x,omitempty"`
+	JumpVx                 *bool  `json:"jump_vx,omitempty"`
+	Clip                   *bool  `json:"clip,omitempty"`
+	VFReset                *bool  `json:"vf_reset,omitempty"`
+	DisplayWait            *bool  `json:"display_wait,omitempty"`
+}
+
+//ConfigError is a problem with a single config field.
+type ConfigError struct {
+	//Field is the path to the field, e.g. "keymap.A" or "quirks.preset".
+	Field string
+	Msg   string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Msg)
+}
+
+//ConfigErrors holds every problem found in a config.
+type ConfigErrors []*ConfigError
+
+func (l ConfigErrors) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+//DefaultConfigPath returns where the config is looked for when -config isn't given,
+//chip8/config.json in the user's config directory.
+func DefaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find user config directory: %v", err)
+	}
+	return filepath.Join(dir, "chip8", "config.json"), nil
+}
+
+//LoadConfig reads and validates the config file.
+//The error is a ConfigErrors if the file was read but has invalid settings.
+func LoadConfig(name string) (*Config, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config: %v", err)
+	}
+
+	var cfg Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode config %s: %v", name, err)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+//Validate checks every field, returning a ConfigErrors naming each bad one.
+//Key names are only checked by Input.Init, looking them up needs SDL.
+func (c *Config) Validate() error {
+	var errs ConfigErrors
+	errorf := func(field string, format string, args ...interface{}) {
+		errs = append(errs, &ConfigError{Field: field, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	hostkeys := make(map[string]string)
+	for _, k := range sortedKeys(c.Keymap) {
+		field := "keymap." + k
+		if _, err := parseKey(k); err != nil {
+			errorf(field, "%v", err)
+			continue
+		}
+		if len(c.Keymap[k]) == 0 {
+			errorf(field, "needs at least one key")
+		}
+		for _, name := range c.Keymap[k] {
+			if other, ok := hostkeys[strings.ToUpper(name)]; ok {
+				errorf(field, "%q is already mapped to %s", name, other)
+				continue
+			}
+			hostkeys[strings.ToUpper(name)] = k
+		}
+	}
+
+	if c.Scale < 0 {
+		errorf("scale", "must be positive, got %d", c.Scale)
+	}
+	if c.Palette != "" {
+		if _, err := LookupPalette(c.Palette); err != nil {
+			errorf("palette", "%v", err)
+		}
+	}
+	if c.Foreground != "" {
+		if _, err := ParseColour(c.Foreground); err != nil {
+			errorf("foreground", "%v", err)
+		}
+	}
+	if c.Background != "" {
+		if _, err := ParseColour(c.Background); err != nil {
+			errorf("background", "%v", err)
+		}
+	}
+	if c.Filter != "" {
+		if _, err := NewFilter(c.Filter, DefaultFilterStrength); err != nil {
+			errorf("filter", "%v", err)
+		}
+	}
+	if c.FilterStrength < 0 {
+		errorf("filter_strength", "must be positive, got %d", c.FilterStrength)
+	}
+	if c.IPS < 0 {
+		errorf("ips", "must be positive, got %d", c.IPS)
+	}
+	if c.Quirks != nil && c.Quirks.Preset != "" {
+		if _, err := LookupQuirks(c.Quirks.Preset); err != nil {
+			errorf("quirks.preset", "%v", err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+//Keys returns the keymap with the defaults filled in for keys the config leaves out.
+//The config must be valid.
+func (c *Config) Keys() map[uint8][]string {
+	keys := make(map[uint8][]string, len(DefaultKeymap))
+	configured := make(map[uint8]bool)
+	taken := make(map[string]bool)
+	for k, names := range c.Keymap {
+		key, _ := parseKey(k)
+		keys[key] = names
+		configured[key] = true
+		for _, name := range names {
+			taken[strings.ToUpper(name)] = true
+		}
+	}
+
+	//Defaults for the rest, minus keyboard keys the config has taken so one key doesn't press two buttons.
+	for k, names := range DefaultKeymap {
+		if configured[k] {
+			continue
+		}
+		for _, name := range names {
+			if !taken[strings.ToUpper(name)] {
+				keys[k] = append(keys[k], name)
+			}
+		}
+	}
+	return keys
+}
+
+//ApplyQuirks returns q with the config's preset and overrides applied.
+//The config must be valid.
+func (c *Config) ApplyQuirks(q Quirks) Quirks {
+	if c.Quirks == nil {
+		return q
+	}
+	if c.Quirks.Preset != "" {
+		q, _ = LookupQuirks(c.Quirks.Preset)
+	}
+	overrides := []struct {
+		set *bool
+		to  *bool
+	}{
+		{c.Quirks.ShiftVy, &q.ShiftVy},
+		{c.Quirks.LoadStoreIncrementI, &q.LoadStoreIncrementI},
+		{c.Quirks.LoadStoreIncrementIByX, &q.LoadStoreIncrementIByX},
+		{c.Quirks.JumpVx, &q.JumpVx},
+		{c.Quirks.Clip, &q.Clip},
+		{c.Quirks.VFReset, &q.VFReset},
+		{c.Quirks.DisplayWait, &q.DisplayWait},
+	}
+	for _, o := range overrides {
+		if o.set != nil {
+			*o.to = *o.set
+		}
+	}
+	return q
+}
+
+//ParseColour reads a colour written as #RRGGBB.
+func ParseColour(s string) (color.RGBA, error) {
+	if len(s) != 7 || s[0] != '#' {
+		return color.RGBA{}, fmt.Errorf("colour %q should be written as #RRGGBB", s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("colour %q should be written as #RRGGBB", s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}, nil
+}
+
+func parseKey(s string) (uint8, error) {
+	k, err := strconv.ParseUint(s, 16, 8)
+	if err != nil || len(s) != 1 {
+		return 0, fmt.Errorf("%q is not a key, keys are the hex digits 0-F", s)
+	}
+	return uint8(k), nil
+}
+
+//sortedKeys returns the keymap's keys in order, so errors come out the same every time.
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,107 @@
+package chip8
+
+import (
+	"image/color"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, src string) string {
+	name := filepath.Join(t.TempDir(), "config.json")
+	err := ioutil.WriteFile(name, []byte(src), 0644)
+	if err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return name
+}
+
+func TestLoadConfig(t *testing.T) {
+	name := writeConfig(t, `{
+		"keymap": {"5": ["W", "Up"], "a": ["Q"]},
+		"scale": 10,
+		"foreground": "#33FF66",
+		"ips": 700,
+		"quirks": {"preset": "vip", "clip": false, "load_store_increment_i_by_x": true}
+	}`)
+
+	cfg, err := LoadConfig(name)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.Scale != 10 || cfg.IPS != 700 {
+		t.Errorf("scale and ips - expected: 10, 700; got: %d, %d", cfg.Scale, cfg.IPS)
+	}
+	fg, _ := ParseColour(cfg.Foreground)
+	if fg != (color.RGBA{R: 0x33, G: 0xFF, B: 0x66, A: 0xFF}) {
+		t.Errorf("foreground - got: %v", fg)
+	}
+
+	keys := cfg.Keys()
+	if strings.Join(keys[0x5], ",") != "W,Up" {
+		t.Errorf("keymap 5 - expected: [W Up]; got: %v", keys[0x5])
+	}
+	if strings.Join(keys[0xA], ",") != "Q" {
+		t.Errorf("keymap A - expected: [Q]; got: %v", keys[0xA])
+	}
+	//Q was the default for 4, it now belongs to A.
+	if len(keys[0x4]) != 0 {
+		t.Errorf("keymap 4 - expected no keys; got: %v", keys[0x4])
+	}
+	if strings.Join(keys[0x1], ",") != "1" {
+		t.Errorf("keymap 1 - expected the default [1]; got: %v", keys[0x1])
+	}
+
+	q := cfg.ApplyQuirks(Quirks{})
+	expected := QuirksVIP
+	expected.Clip = false
+	expected.LoadStoreIncrementIByX = true
+	if q != expected {
+		t.Errorf("quirks - expected: %+v; got: %+v", expected, q)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tt := []struct {
+		name     string
+		src      string
+		expected []string
+	}{
+		{name: "Bad key", src: `{"keymap": {"G": ["Q"], "10": ["W"]}}`,
+			expected: []string{`keymap.10: "10" is not a key`, `keymap.G: "G" is not a key`}},
+		{name: "Empty and duplicate keys", src: `{"keymap": {"1": [], "2": ["Q"], "3": ["q"]}}`,
+			expected: []string{"keymap.1: needs at least one key", `keymap.3: "q" is already mapped to 2`}},
+		{name: "Bad numbers", src: `{"scale": -1, "ips": -5}`,
+			expected: []string{"scale: must be positive", "ips: must be positive"}},
+		{name: "Bad colours", src: `{"foreground": "green", "background": "#12345"}`,
+			expected: []string{`foreground: colour "green"`, `background: colour "#12345"`}},
+		{name: "Bad filter", src: `{"filter": "blur", "filter_strength": -1}`,
+			expected: []string{`filter: unknown filter "blur"`, "filter_strength: must be positive"}},
+		{name: "Bad preset", src: `{"quirks": {"preset": "octo"}}`,
+			expected: []string{`quirks.preset: unknown quirks preset "octo"`}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, tc.src))
+			errs, ok := err.(ConfigErrors)
+			if !ok {
+				t.Fatalf("expected ConfigErrors; got: %v", err)
+			}
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("expected %d errors; got: %v", len(tc.expected), errs)
+			}
+			for i, e := range errs {
+				if !strings.HasPrefix(e.Error(), tc.expected[i]) {
+					t.Errorf("expected: %q; got: %q", tc.expected[i], e.Error())
+				}
+			}
+		})
+	}
+
+	_, err := LoadConfig(writeConfig(t, `{"sclae": 2}`))
+	if err == nil || !strings.Contains(err.Error(), `"sclae"`) {
+		t.Errorf("expected an error naming the unknown field; got: %v", err)
+	}
+}
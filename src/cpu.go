@@ -3,8 +3,8 @@ package chip8
 import (
 	"encoding/binary"
 	"fmt"
-
-	"github.com/veandco/go-sdl2/sdl"
+	"io"
+	"time"
 )
 
 const (
@@ -12,6 +12,16 @@ const (
 	PCInit = uint16(512)
 	//SPInit is the inital value for the SP.
 	SPInit = uint8(16)
+
+	//DefaultIPS is the default clock speed in instructions per second.
+	DefaultIPS = 600
+	//FrameRate is the number of frames per second, the same rate as the timers.
+	FrameRate = 60
+
+	//FontAddr is where the 4x5 hex digit sprites are stored.
+	FontAddr = uint16(0)
+	//LargeFontAddr is where the SUPER-CHIP 8x10 hex digit sprites are stored, straight after the small ones.
+	LargeFontAddr = uint16(0x50)
 )
 
 //CPU for Chip8 VM.
@@ -19,10 +29,14 @@ type CPU struct {
 	PC uint16
 	I  uint16
 
-	G     *Graphics
-	Input *Input
+	G     Display
+	Input Keypad
 
 	DT *Timer
+	ST *Timer
+
+	Sound   Beeper
+	beeping bool
 
 	Stack [16]uint16
 	SP    uint8
@@ -31,19 +45,93 @@ type CPU struct {
 
 	// Registers
 	V [16]uint8
+	//RPL are the SUPER-CHIP user flags, saved and loaded with Fx75 and Fx85.
+	RPL [16]uint8
+
+	//Pattern is the XO-CHIP audio pattern buffer, played at the rate set by Pitch.
+	Pattern [16]byte
+	Pitch   uint8
+	//PatternLoaded is set once F002 has loaded a pattern, until then the beeper keeps its own tone.
+	PatternLoaded bool
+
+	//Platform decides which instructions Decode accepts, change it with SetPlatform.
+	Platform Platform
+	//Quirks picks between the behaviours of different interpreters, change it with SetQuirks.
+	Quirks Quirks
+	//nextDraw is the first cycle of the frame after the last draw, for the DisplayWait quirk.
+	nextDraw uint64
+	//Halted is set once the program exits with 00FD, the CPU won't run any more instructions.
+	Halted bool
+
+	//Rand is the source for Cxkk, use Seed to make runs repeatable.
+	//Save states only keep its position if it's a SeededRandom.
+	Rand RandomSource
+
+	//Cycles is the number of instructions executed so far.
+	Cycles uint64
+	//IPS is the clock speed in instructions per second.
+	IPS int
+	//TimerDivider steps the timers once every TimerDivider instructions, making
+	//them deterministic. Zero leaves the timers to count down on their own.
+	TimerDivider uint64
+
+	//Until stops Run once Cycles reaches it, zero runs until the window is closed.
+	Until uint64
+	//Frames is the number of frames run so far.
+	Frames uint64
+	//Taps are handed the screen at the end of every frame.
+	Taps []FrameTap
+	//Rewind, if set, captures the state every frame so the rewind hotkey can go back to it.
+	Rewind *Rewind
+	//rewinding is set while the rewind hotkey is held.
+	rewinding bool
+	//movie is the movie being recorded or replayed, rewinding and save states are off while it's set.
+	movie *Movie
+	//Recorder is the GIF recorder toggled by the record hotkey, also in Taps once set.
+	Recorder *Recorder
+
+	//StatePath is the file the save state hotkeys write to and load from.
+	StatePath string
+	//ScreenshotPath is the start of the file names the screenshot and record hotkeys write to,
+	//a number and the extension are added.
+	ScreenshotPath string
 }
 
 //NewCPU returns a new CPU blank struct.
-func NewCPU(m *Memory, g *Graphics, in *Input, dt *Timer) *CPU {
-	return &CPU{
+func NewCPU(m *Memory, g Display, in Keypad, dt *Timer, st *Timer, b Beeper, q Quirks) *CPU {
+	c := &CPU{
 		PC:     PCInit,
 		I:      0,
 		G:      g,
 		Input:  in,
 		DT:     dt,
+		ST:     st,
+		Sound:  b,
 		SP:     SPInit,
 		Memory: m,
+		IPS:    DefaultIPS,
+		Pitch:  DefaultPitch,
+		Rand:   NewSeededRandom(time.Now().UnixNano()),
 	}
+	c.SetQuirks(q)
+	return c
+}
+
+//Seed restarts the random numbers used by Cxkk from the seed, the same seed gives the same numbers.
+func (c *CPU) Seed(seed int64) {
+	c.Rand = NewSeededRandom(seed)
+}
+
+//SetQuirks changes the quirks, passing Clip on to the display.
+func (c *CPU) SetQuirks(q Quirks) {
+	c.Quirks = q
+	c.G.SetClip(q.Clip)
+}
+
+//SetPlatform picks the instruction set, XO-CHIP also extends the memory to 64KB.
+func (c *CPU) SetPlatform(p Platform) {
+	c.Platform = p
+	c.Memory.SetExtended(p >= PlatformXOCHIP)
 }
 
 //Init sets up some inital parameters:
@@ -85,61 +173,221 @@ func (c *CPU) Init() error {
 		{0xF0, 0x80, 0xF0, 0x80, 0x80},
 	}
 
-	addr := uint16(0)
+	addr := FontAddr
 
 	for _, sp := range sprites {
-		err := c.writeSprite(sp, addr)
+		err := c.writeSprite(sp[:], addr)
 		if err != nil {
 			return fmt.Errorf("could not write sprite %v: %v", sp, err)
 		}
 		addr += 5
 	}
 
+	//SUPER-CHIP large sprites, Octo's set which includes A-F.
+	largeSprites := [16][10]byte{
+		//0
+		{0xFF, 0xFF, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF},
+		//1
+		{0x18, 0x78, 0x78, 0x18, 0x18, 0x18, 0x18, 0x18, 0xFF, 0xFF},
+		//2
+		{0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF},
+		//3
+		{0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF},
+		//4
+		{0xC3, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, 0x03, 0x03, 0x03, 0x03},
+		//5
+		{0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF},
+		//6
+		{0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF},
+		//7
+		{0xFF, 0xFF, 0x03, 0x03, 0x06, 0x0C, 0x18, 0x18, 0x18, 0x18},
+		//8
+		{0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF},
+		//9
+		{0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF},
+		//A
+		{0x7E, 0xFF, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, 0xC3, 0xC3, 0xC3},
+		//B
+		{0xFC, 0xFC, 0xC3, 0xC3, 0xFC, 0xFC, 0xC3, 0xC3, 0xFC, 0xFC},
+		//C
+		{0x3C, 0xFF, 0xC3, 0xC0, 0xC0, 0xC0, 0xC0, 0xC3, 0xFF, 0x3C},
+		//D
+		{0xFC, 0xFE, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xFE, 0xFC},
+		//E
+		{0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF},
+		//F
+		{0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC0, 0xC0, 0xC0, 0xC0},
+	}
+
+	addr = LargeFontAddr
+
+	for _, sp := range largeSprites {
+		err := c.writeSprite(sp[:], addr)
+		if err != nil {
+			return fmt.Errorf("could not write sprite %v: %v", sp, err)
+		}
+		addr += 10
+	}
+
 	return nil
 }
 
-func (c *CPU) writeSprite(sprite [5]byte, addr uint16) error {
+func (c *CPU) writeSprite(sprite []byte, addr uint16) error {
 	for i := 0; i < len(sprite); i++ {
-		c.Memory.Write(sprite[i], addr+uint16(i))
+		err := c.Memory.Write(sprite[i], addr+uint16(i))
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-//Run is the main loop for the Chip8 emulator.
-func (c *CPU) Run() error {
-	running := true
-	for running {
-		inst, err := c.Fetch()
+//StepResult describes a single executed instruction.
+type StepResult struct {
+	//PCBefore is the address the instruction was fetched from.
+	PCBefore uint16
+	//PCAfter is where the PC ended up once the instruction ran.
+	PCAfter uint16
+	Opcode  uint16
+	Err     error
+}
+
+//InstructionsPerFrame is how many instructions RunFrame executes, at least one.
+func (c *CPU) InstructionsPerFrame() int {
+	n := c.IPS / FrameRate
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+//RunFrame executes one 60Hz frame worth of instructions then steps the timers,
+//unless the CPU is stepping them itself with TimerDivider, captures the state for Rewind,
+//and hands the screen to the Taps.
+//Returns the result of the last instruction run, stopping early on an error.
+func (c *CPU) RunFrame() StepResult {
+	res := c.RunCycles(c.InstructionsPerFrame())
+	if res.Err != nil {
+		return res
+	}
+
+	if c.TimerDivider == 0 {
+		err := c.Step60Hz()
 		if err != nil {
-			return fmt.Errorf("could not fetch instruction: %v", err)
+			res.Err = fmt.Errorf("could not step timers: %v", err)
+			return res
 		}
-		fmt.Printf("Instruction: %x\n", inst)
-		handler, err := c.Decode(inst)
+	}
+
+	if c.Rewind != nil {
+		err := c.Rewind.Capture(c)
 		if err != nil {
-			return fmt.Errorf("could not decode instruction: %v", err)
+			res.Err = fmt.Errorf("could not capture rewind state: %v", err)
+			return res
 		}
-		err = handler()
-		if err != nil {
-			return fmt.Errorf("something went wrong in instruction handler: %v", err)
+	}
+
+	c.Frames++
+	if len(c.Taps) > 0 {
+		frame := c.G.Frame()
+		for _, tap := range c.Taps {
+			err := tap.TapFrame(c.Frames, frame)
+			if err != nil {
+				res.Err = fmt.Errorf("could not tap frame %d: %v", c.Frames, err)
+				return res
+			}
 		}
+	}
+	return res
+}
 
-		err = c.G.PaintSurface()
-		if err != nil {
-			return fmt.Errorf("could not paint surface: %v", err)
+//RunCycles executes n instructions.
+//Returns the result of the last instruction run, stopping early on an error.
+func (c *CPU) RunCycles(n int) StepResult {
+	var res StepResult
+	for i := 0; i < n; i++ {
+		res = c.Step()
+		if res.Err != nil {
+			break
 		}
+	}
+	return res
+}
 
-		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-			switch event.(type) {
-			case *sdl.QuitEvent:
-				println("Quit")
-				running = false
-				break
-			}
+//Step executes a single instruction, doesn't touch the window or poll for events.
+func (c *CPU) Step() (res StepResult) {
+	res.PCBefore = c.PC
+	defer func() { res.PCAfter = c.PC }()
+
+	if c.Halted {
+		res.Err = fmt.Errorf("program has exited")
+		return res
+	}
+
+	err := c.Input.Update(c.Cycles)
+	if err != nil {
+		res.Err = fmt.Errorf("could not update keypad: %v", err)
+		return res
+	}
+	inst, err := c.Fetch()
+	if err != nil {
+		res.Err = fmt.Errorf("could not fetch instruction: %v", err)
+		return res
+	}
+	res.Opcode = inst
+	handler, err := c.Decode(inst)
+	if err != nil {
+		res.Err = fmt.Errorf("could not decode instruction: %v", err)
+		return res
+	}
+
+	//Point at the next instruction before executing, so jumps and calls land where they say.
+	c.PC += 2
+	err = handler()
+	if err != nil {
+		//Leave the PC on the instruction that failed.
+		c.PC = res.PCBefore
+		res.Err = fmt.Errorf("something went wrong in instruction handler: %v", err)
+		return res
+	}
+
+	err = c.UpdateSound()
+	if err != nil {
+		res.Err = fmt.Errorf("could not update sound: %v", err)
+		return res
+	}
+
+	c.Cycles++
+
+	if c.TimerDivider != 0 && c.Cycles%c.TimerDivider == 0 {
+		err = c.Step60Hz()
+		if err != nil {
+			res.Err = fmt.Errorf("could not step timers: %v", err)
+			return res
 		}
+	}
+	return res
+}
 
-		c.PC += 2
+//WriteRegisters prints the registers, timers and stack.
+func (c *CPU) WriteRegisters(w io.Writer) {
+	fmt.Fprintf(w, "PC: 0x%03X  I: 0x%03X  SP: %d  cycles: %d\n", c.PC, c.I, c.SP, c.Cycles)
+	for i := 0; i < len(c.V); i++ {
+		fmt.Fprintf(w, "V%X: 0x%02X", i, c.V[i])
+		if i%8 == 7 {
+			fmt.Fprintf(w, "\n")
+		} else {
+			fmt.Fprintf(w, "  ")
+		}
 	}
-	return nil
+	dt, _ := c.DT.Get()
+	st, _ := c.ST.Get()
+	fmt.Fprintf(w, "DT: %d  ST: %d\n", dt, st)
+	fmt.Fprintf(w, "Stack:")
+	for i := int(SPInit) - 1; i >= int(c.SP); i-- {
+		fmt.Fprintf(w, " 0x%03X", c.Stack[i])
+	}
+	fmt.Fprintf(w, "\n")
 }
 
 //Fetch the instruction the PC is currently pointing at.
@@ -164,125 +412,69 @@ func (c *CPU) Fetch() (uint16, error) {
 }
 
 //Decode the instruction and return the relevant handler function.
+//Instructions are looked up in the opcode table, see opcodes.go.
 func (c *CPU) Decode(inst uint16) (func() error, error) {
-	switch op := inst & 0xF000; op {
-	case 0x0000:
-		switch t := inst & 0x00FF; t {
-		//Clear the display.
-		case 0x00E0:
-			return func() error { return c.ClearScreen(inst) }, nil
-		//Return from a subroutine.
-		case 0x00EE:
-			return func() error { return c.Return(inst) }, nil
-		}
-	//Jump to location nnn.
-	case 0x1000:
-		return func() error { return c.Jump(inst) }, nil
-	//Call subroutine at nnn.
-	case 0x2000:
-		return func() error { return c.Call(inst) }, nil
-	//Skip next instruction if Vx = kk.
-	case 0x3000:
-		return func() error { return c.SkipEqualVal(inst) }, nil
-	//Skip next instruction if Vx != kk.
-	case 0x4000:
-		return func() error { return c.SkipNotEqualVal(inst) }, nil
-	//Skip next instruction if Vx = Vy.
-	case 0x5000:
-		return func() error { return c.SkipEqualReg(inst) }, nil
-	//Set Vx = kk.
-	case 0x6000:
-		return func() error { return c.LoadValue(inst) }, nil
-	//Set Vx = Vx + kk.
-	case 0x7000:
-		return func() error { return c.AddValue(inst) }, nil
-	//Set instructions.
-	case 0x8000:
-		switch t := inst & 0x000F; t {
-		//Set Vx = Vy.
-		case 0x0000:
-			return func() error { return c.LoadReg(inst) }, nil
-		//Set Vx = Vx OR Vy.
-		case 0x0001:
-			return func() error { return c.Or(inst) }, nil
-		//Set Vx = Vx AND Vy.
-		case 0x0002:
-			return func() error { return c.And(inst) }, nil
-		//Set Vx = Vx XOR Vy.
-		case 0x0003:
-			return func() error { return c.Xor(inst) }, nil
-		//Set Vx = Vx + Vy, set VF = carry.
-		case 0x0004:
-			return func() error { return c.Add(inst) }, nil
-		//Set Vx = Vx - Vy, set VF = NOT borrow.
-		case 0x0005:
-			return func() error { return c.Sub(inst) }, nil
-		//Set Vx = Vx SHR 1.
-		case 0x0006:
-			return func() error { return c.ShiftRight(inst) }, nil
-		//Set Vx = Vy - Vx, set VF = NOT borrow.
-		case 0x0007:
-			return func() error { return c.SubN(inst) }, nil
-		//Set Vx = Vx SHL 1.
-		case 0x000E:
-			return func() error { return c.ShiftLeft(inst) }, nil
+	op, ok := LookupOpcode(inst)
+	if !ok {
+		//Unknown Fx instructions are let through so programs keep running.
+		if CheckInst(inst, 0xF000) {
+			return func() error { return c.NotImplemented(inst) }, nil
 		}
-	//Skip next instruction if Vx != Vy.
-	case 0x9000:
-		return func() error { return c.SkipNotEqualReg(inst) }, nil
-	//Set I = nnn.
-	case 0xA000:
-		return func() error { return c.LoadI(inst) }, nil
-	//Jump to location nnn + V0.
-	case 0xB000:
-		return func() error { return c.JumpWithReg(inst) }, nil
-	//Set Vx = random byte AND kk.
-	case 0xC000:
-		return func() error { return c.RandomAnd(inst) }, nil
-	//Display n-byte sprite starting at memory location I at (Vx, Vy), set VF = collision.
-	case 0xD000:
-		return func() error { return c.DrawSprite(inst) }, nil
-	case 0xE000:
-		switch t := inst & 0x00FF; t {
-		//Skip next instruction if key with the value of Vx is pressed.
-		case 0x009E:
-			return func() error { return c.SkipIfKey(inst) }, nil
-		//Skip next instruction if key with the value of Vx is not pressed.
-		case 0x00A1:
-			return func() error { return c.SkipIfNotKey(inst) }, nil
+		return nil, fmt.Errorf("invalid instruction: %x", inst)
+	}
+	if op.Platform > c.Platform {
+		return nil, fmt.Errorf("%x is a %v instruction, running as %v", inst, op.Platform, c.Platform)
+	}
+
+	return func() error { return op.handler(c, inst) }, nil
+}
+
+//skip moves the PC past the next instruction, which is 4 bytes long if it's XO-CHIP's F000 nnnn.
+func (c *CPU) skip() error {
+	if c.Platform >= PlatformXOCHIP {
+		next, err := c.Fetch()
+		if err != nil {
+			return fmt.Errorf("could not fetch next instruction: %v", err)
 		}
-	case 0xF000:
-		switch t := inst & 0x00FF; t {
-		//Set Vx = delay timer value.
-		case 0x0007:
-			return func() error { return c.SetRegDT(inst) }, nil
-		//Wait for a key press, store the value of the key in Vx.
-		case 0x000A:
-			return func() error { return c.WaitForKey(inst) }, nil
-		//Set delay timer = Vx.
-		case 0x0015:
-			return func() error { return c.SetDT(inst) }, nil
-		//Set I = I + Vx.
-		case 0x001E:
-			return func() error { return c.AddIReg(inst) }, nil
-		//Set I = location of sprite for digit Vx.
-		case 0x0029:
-			return func() error { return c.SetISprite(inst) }, nil
-		//Store BCD representation of Vx in memory locations I, I+1, and I+2.
-		case 0x0033:
-			return func() error { return c.SplitDecimal(inst) }, nil
-		//Store registers V0 through Vx in memory starting at location I.
-		case 0x0055:
-			return func() error { return c.StoreRegs(inst) }, nil
-		//Read registers V0 through Vx from memory starting at location I.
-		case 0x0065:
-			return func() error { return c.LoadRegs(inst) }, nil
-		default:
-			return func() error { return c.NotImplemented(inst) }, nil
+		if next == 0xF000 {
+			c.PC += 4
+			return nil
 		}
 	}
+	c.PC += 2
+	return nil
+}
+
+//Step60Hz advances the delay and sound timers by one 60Hz tick.
+//Wall-clock timers ignore this, they are already counting down.
+func (c *CPU) Step60Hz() error {
+	c.DT.Step60Hz()
+	c.ST.Step60Hz()
+
+	err := c.UpdateSound()
+	if err != nil {
+		return fmt.Errorf("could not update sound: %v", err)
+	}
+	return nil
+}
 
-	return nil, fmt.Errorf("invalid instruction: %x", inst)
+//UpdateSound starts or stops the beeper to match the sound timer.
+func (c *CPU) UpdateSound() error {
+	t, err := c.ST.Get()
+	if err != nil {
+		return fmt.Errorf("could not get sound timer: %v", err)
+	}
+
+	on := t > 0
+	if on == c.beeping {
+		return nil
+	}
+	c.beeping = on
+
+	if on {
+		return c.Sound.Start(c.Cycles)
+	}
+	return c.Sound.Stop(c.Cycles)
 }
 
 //Push data onto stack and decrement stack pointer.
@@ -45,3 +45,151 @@ func TestPush(t *testing.T) {
 		}
 	})
 }
+
+func TestUpdateSound(t *testing.T) {
+	c8 := setup()
+	b := NewRecordingBeeper()
+	c8.Sound = b
+
+	steps := []struct {
+		cycle uint64
+		st    uint
+	}{
+		{cycle: 3, st: 10},
+		//Already beeping, nothing new to record.
+		{cycle: 4, st: 9},
+		{cycle: 12, st: 0},
+		{cycle: 20, st: 0},
+		{cycle: 25, st: 2},
+	}
+	for _, s := range steps {
+		c8.Cycles = s.cycle
+		c8.ST.Set(s.st)
+		err := c8.UpdateSound()
+		if err != nil {
+			t.Fatalf("failed to update sound: %v", err)
+		}
+	}
+
+	expected := []BeepEvent{{Cycle: 3, On: true}, {Cycle: 12, On: false}, {Cycle: 25, On: true}}
+	if len(b.Events) != len(expected) {
+		t.Fatalf("expected: %v; got: %v", expected, b.Events)
+	}
+	for i := range expected {
+		if b.Events[i] != expected[i] {
+			t.Errorf("expected: %v; got: %v", expected, b.Events)
+		}
+	}
+}
+
+func TestInstructionsPerFrame(t *testing.T) {
+	tt := []struct {
+		name     string
+		ips      int
+		expected int
+	}{
+		{name: "Default speed", ips: DefaultIPS, expected: 10},
+		{name: "Fast", ips: 1200, expected: 20},
+		{name: "Rounds down", ips: 500, expected: 8},
+		{name: "Slower than frame rate", ips: 30, expected: 1},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			c8.IPS = tc.ips
+			if got := c8.InstructionsPerFrame(); got != tc.expected {
+				t.Errorf("expected: %d; got: %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestStep(t *testing.T) {
+	tt := []struct {
+		name      string
+		program   []byte
+		platform  Platform
+		steps     int
+		pc        uint16
+		reg       treg
+		expectErr bool
+	}{
+		{name: "Load value", program: []byte{0x6A, 0x07}, steps: 1, pc: 0x202, reg: treg{reg: 0xA, value: 7}, expectErr: false},
+		{name: "Jump lands on target", program: []byte{0x12, 0x04, 0x6A, 0x01, 0x6A, 0x02}, steps: 2, pc: 0x206, reg: treg{reg: 0xA, value: 2}, expectErr: false},
+		{name: "Call and return", program: []byte{0x22, 0x06, 0x6B, 0x03, 0x12, 0x02, 0x6B, 0x09, 0x00, 0xEE}, steps: 4, pc: 0x204, reg: treg{reg: 0xB, value: 3}, expectErr: false},
+		{name: "Skip next instruction", program: []byte{0x30, 0x00, 0x6C, 0x01, 0x6C, 0x02}, steps: 2, pc: 0x206, reg: treg{reg: 0xC, value: 2}, expectErr: false},
+		{name: "Invalid instruction", program: []byte{0x00, 0x00}, steps: 1, pc: PCInit, expectErr: true},
+		{name: "Failing instruction", program: []byte{0x00, 0xEE}, steps: 1, pc: PCInit, expectErr: true},
+		{name: "SCHIP instruction on CHIP-8", program: []byte{0x00, 0xFF}, steps: 1, pc: PCInit, expectErr: true},
+		{name: "SCHIP instruction on SCHIP", program: []byte{0x00, 0xFF, 0x6A, 0x05}, platform: PlatformSCHIP, steps: 2, pc: 0x204, reg: treg{reg: 0xA, value: 5}, expectErr: false},
+		{name: "Nothing runs after exit", program: []byte{0x00, 0xFD, 0x6A, 0x05}, platform: PlatformSCHIP, steps: 2, pc: 0x202, expectErr: true},
+		{name: "Long I load", program: []byte{0xF0, 0x00, 0x12, 0x34, 0x6A, 0x05}, platform: PlatformXOCHIP, steps: 2, pc: 0x206, reg: treg{reg: 0xA, value: 5}, expectErr: false},
+		{name: "Skip over long I load", program: []byte{0x30, 0x00, 0xF0, 0x00, 0x12, 0x34, 0x6A, 0x05}, platform: PlatformXOCHIP, steps: 2, pc: 0x208, reg: treg{reg: 0xA, value: 5}, expectErr: false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			c8.SetPlatform(tc.platform)
+			err := c8.LoadProgram(tc.program)
+			if err != nil {
+				t.Fatalf("failed to load program: %v", err)
+			}
+			res := c8.RunCycles(tc.steps)
+			if res.Err != nil {
+				if tc.expectErr == true {
+					if res.PCAfter != tc.pc || c8.PC != tc.pc {
+						t.Errorf("PC - expected: %x; got: %x, PCAfter %x", tc.pc, c8.PC, res.PCAfter)
+					}
+					return
+				}
+				t.Fatalf("failed to run: %v", res.Err)
+			}
+			if res.PCAfter != tc.pc || c8.PC != tc.pc {
+				t.Errorf("PC - expected: %x; got: %x", tc.pc, c8.PC)
+			}
+			if c8.V[tc.reg.reg] != tc.reg.value {
+				t.Errorf("V[%x] - expected: %d; got: %d", tc.reg.reg, tc.reg.value, c8.V[tc.reg.reg])
+			}
+			if c8.Cycles != uint64(tc.steps) {
+				t.Errorf("Cycles - expected: %d; got: %d", tc.steps, c8.Cycles)
+			}
+		})
+	}
+}
+
+func TestStepResult(t *testing.T) {
+	c8 := setup()
+	c8.LoadProgram([]byte{0x12, 0x08})
+
+	res := c8.Step()
+	if res.Err != nil {
+		t.Fatalf("failed to step: %v", res.Err)
+	}
+	expected := StepResult{PCBefore: 0x200, PCAfter: 0x208, Opcode: 0x1208}
+	if res != expected {
+		t.Errorf("expected: %+v; got: %+v", expected, res)
+	}
+}
+
+func TestRunFrame(t *testing.T) {
+	c8 := setup()
+	//Loop on the spot.
+	c8.LoadProgram([]byte{0x12, 0x00})
+	c8.DT.Set(5)
+
+	for i := 0; i < 2; i++ {
+		res := c8.RunFrame()
+		if res.Err != nil {
+			t.Fatalf("failed to run frame: %v", res.Err)
+		}
+	}
+
+	if c8.Cycles != uint64(2*c8.InstructionsPerFrame()) {
+		t.Errorf("Cycles - expected: %d; got: %d", 2*c8.InstructionsPerFrame(), c8.Cycles)
+	}
+	if dt, _ := c8.DT.Get(); dt != 3 {
+		t.Errorf("DT - expected: 3; got: %d", dt)
+	}
+}
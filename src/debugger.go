@@ -0,0 +1,504 @@
+package chip8
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const debuggerHelp = `Commands:
+  s, step [n]             Execute n instructions (default 1).
+  c, continue [n]         Run until a breakpoint, watchpoint or error, at most n instructions if given.
+                          Ctrl+C or closing the window stops it and goes back to the prompt.
+  b, break <addr>         Set a breakpoint at addr.
+  d, delete <addr>        Remove the breakpoint at addr.
+  w, watch <target>       Stop when target changes. Target is V0-VF, I or mem <addr> [len].
+  unwatch <n>             Remove watchpoint n.
+  l, list                 List breakpoints and watchpoints.
+  r, regs                 Show the registers, stack and timers.
+  set <reg> <value>       Set V0-VF, I, PC, SP, DT or ST.
+  x, mem <addr> [len]     Show len bytes of memory starting at addr (default 16).
+  poke <addr> <byte>...   Write bytes to memory starting at addr.
+  dis [addr] [n]          Disassemble n instructions from addr (default around the PC).
+  h, help                 Show this help.
+  q, quit                 Exit the debugger.
+Numbers are decimal unless prefixed with 0x.
+`
+
+//watchpoint stops execution when the value it reads changes.
+type watchpoint struct {
+	name string
+	read func() ([]byte, error)
+	last []byte
+}
+
+//Debugger is an interactive command line debugger for the CPU.
+type Debugger struct {
+	c   *CPU
+	in  *bufio.Scanner
+	out io.Writer
+
+	breakpoints map[uint16]bool
+	watchpoints []*watchpoint
+
+	//steps counts instructions run by the debugger, used to step the timers once a frame.
+	steps int
+
+	//interrupt gets Ctrl+C while instructions are running, to stop and go back to the prompt.
+	interrupt chan os.Signal
+}
+
+//eventPoller is a Display with a window that needs its events handled, like Graphics.
+type eventPoller interface {
+	//PollEvents handles pending window events, returning false if the window was closed.
+	PollEvents() bool
+}
+
+//NewDebugger returns a debugger for the CPU that reads commands from in and writes to out.
+func NewDebugger(c *CPU, in io.Reader, out io.Writer) *Debugger {
+	return &Debugger{
+		c:           c,
+		in:          bufio.NewScanner(in),
+		out:         out,
+		breakpoints: make(map[uint16]bool),
+		interrupt:   make(chan os.Signal, 1),
+	}
+}
+
+//Run reads and executes commands until quit or the input runs out.
+func (d *Debugger) Run() error {
+	d.printf("CHIP-8 debugger, type help for a list of commands.\n")
+	d.disassemble(d.c.PC, 1)
+	for {
+		d.printf("(chip8) ")
+		if !d.in.Scan() {
+			d.printf("\n")
+			return d.in.Err()
+		}
+		args := strings.Fields(d.in.Text())
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "q" || args[0] == "quit" {
+			return nil
+		}
+		err := d.Exec(args[0], args[1:])
+		if err != nil {
+			d.printf("error: %v\n", err)
+		}
+	}
+}
+
+//Exec runs a single debugger command.
+func (d *Debugger) Exec(cmd string, args []string) error {
+	switch cmd {
+	case "s", "step":
+		n, err := optionalCount(args, 0, 1)
+		if err != nil {
+			return err
+		}
+		//run takes 0 to mean no limit, which would skip the breakpoints.
+		if n < 1 {
+			return fmt.Errorf("step count must be at least 1")
+		}
+		return d.run(int(n), false)
+	case "c", "continue":
+		n, err := optionalCount(args, 0, 0)
+		if err != nil {
+			return err
+		}
+		return d.run(int(n), true)
+	case "b", "break":
+		addr, err := requiredNumber(args, 0, "address")
+		if err != nil {
+			return err
+		}
+		d.breakpoints[uint16(addr)] = true
+		d.printf("breakpoint set at 0x%03X\n", addr)
+	case "d", "delete":
+		addr, err := requiredNumber(args, 0, "address")
+		if err != nil {
+			return err
+		}
+		if !d.breakpoints[uint16(addr)] {
+			return fmt.Errorf("no breakpoint at 0x%03X", addr)
+		}
+		delete(d.breakpoints, uint16(addr))
+	case "w", "watch":
+		return d.watch(args)
+	case "unwatch":
+		n, err := requiredNumber(args, 0, "watchpoint number")
+		if err != nil {
+			return err
+		}
+		if int(n) >= len(d.watchpoints) {
+			return fmt.Errorf("no watchpoint %d", n)
+		}
+		d.watchpoints = append(d.watchpoints[:n], d.watchpoints[n+1:]...)
+	case "l", "list":
+		d.list()
+	case "r", "regs":
+		d.registers()
+	case "set":
+		return d.set(args)
+	case "x", "mem":
+		addr, err := requiredNumber(args, 0, "address")
+		if err != nil {
+			return err
+		}
+		n, err := optionalCount(args, 1, 16)
+		if err != nil {
+			return err
+		}
+		return d.memory(uint16(addr), int(n))
+	case "poke":
+		return d.poke(args)
+	case "dis":
+		//Default to a couple of instructions either side of the PC.
+		start := d.c.PC
+		if start >= 4 {
+			start -= 4
+		}
+		addr, err := optionalNumber(args, 0, uint64(start))
+		if err != nil {
+			return err
+		}
+		n, err := optionalCount(args, 1, 5)
+		if err != nil {
+			return err
+		}
+		d.disassemble(uint16(addr), int(n))
+	case "h", "help":
+		d.printf("%s", debuggerHelp)
+	default:
+		return fmt.Errorf("unknown command %q, type help for a list of commands", cmd)
+	}
+	return nil
+}
+
+//run executes up to n instructions, n of zero means no limit.
+//When stopAtBreak is set it stops at breakpoints, it always stops on watchpoints, errors,
+//Ctrl+C and the window being closed.
+func (d *Debugger) run(n int, stopAtBreak bool) error {
+	defer func() { d.disassemble(d.c.PC, 1) }()
+
+	signal.Notify(d.interrupt, os.Interrupt)
+	defer signal.Stop(d.interrupt)
+
+	for i := 0; n == 0 || i < n; i++ {
+		if stopAtBreak && i > 0 && d.breakpoints[d.c.PC] {
+			d.printf("breakpoint at 0x%03X\n", d.c.PC)
+			break
+		}
+		select {
+		case <-d.interrupt:
+			d.printf("interrupted at 0x%03X\n", d.c.PC)
+			return nil
+		default:
+		}
+
+		res := d.c.Step()
+		if res.Err != nil {
+			return fmt.Errorf("at 0x%03X: %v", res.PCBefore, res.Err)
+		}
+		open, err := d.frame()
+		if err != nil {
+			return err
+		}
+		if !open {
+			d.printf("window closed at 0x%03X\n", d.c.PC)
+			return nil
+		}
+
+		if d.checkWatchpoints() {
+			break
+		}
+	}
+
+	err := d.c.G.PaintSurface()
+	if err != nil {
+		return fmt.Errorf("could not paint surface: %v", err)
+	}
+	return nil
+}
+
+//frame steps the timers, repaints the screen and handles window events once a frame's worth
+//of instructions has run. Returns false if the window was closed.
+func (d *Debugger) frame() (bool, error) {
+	d.steps++
+	if d.steps%d.c.InstructionsPerFrame() != 0 {
+		return true, nil
+	}
+	if d.c.TimerDivider == 0 {
+		err := d.c.Step60Hz()
+		if err != nil {
+			return true, fmt.Errorf("could not step timers: %v", err)
+		}
+	}
+	err := d.c.G.PaintSurface()
+	if err != nil {
+		return true, fmt.Errorf("could not paint surface: %v", err)
+	}
+	if p, ok := d.c.G.(eventPoller); ok {
+		return p.PollEvents(), nil
+	}
+	return true, nil
+}
+
+//checkWatchpoints reports any watched values that changed, returns true if any did.
+func (d *Debugger) checkWatchpoints() bool {
+	hit := false
+	for i, w := range d.watchpoints {
+		v, err := w.read()
+		if err != nil {
+			continue
+		}
+		if string(v) != string(w.last) {
+			d.printf("watchpoint %d: %s changed % X -> % X\n", i, w.name, w.last, v)
+			w.last = v
+			hit = true
+		}
+	}
+	return hit
+}
+
+func (d *Debugger) watch(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing watch target")
+	}
+
+	w := &watchpoint{name: strings.ToUpper(args[0])}
+	switch target := strings.ToUpper(args[0]); target {
+	case "I":
+		w.read = func() ([]byte, error) { return []byte{byte(d.c.I >> 8), byte(d.c.I)}, nil }
+	case "MEM":
+		addr, err := requiredNumber(args, 1, "address")
+		if err != nil {
+			return err
+		}
+		n, err := optionalCount(args, 2, 1)
+		if err != nil {
+			return err
+		}
+		w.name = fmt.Sprintf("mem[0x%03X:0x%03X]", addr, addr+n)
+		w.read = func() ([]byte, error) { return d.readMemory(uint16(addr), int(n)) }
+	default:
+		reg, err := parseRegister(target)
+		if err != nil {
+			return err
+		}
+		w.read = func() ([]byte, error) { return []byte{d.c.V[reg]}, nil }
+	}
+
+	var err error
+	w.last, err = w.read()
+	if err != nil {
+		return err
+	}
+	d.watchpoints = append(d.watchpoints, w)
+	d.printf("watchpoint %d: %s\n", len(d.watchpoints)-1, w.name)
+	return nil
+}
+
+func (d *Debugger) list() {
+	var addrs []int
+	for addr := range d.breakpoints {
+		addrs = append(addrs, int(addr))
+	}
+	sort.Ints(addrs)
+	for _, addr := range addrs {
+		d.printf("breakpoint 0x%03X\n", addr)
+	}
+	for i, w := range d.watchpoints {
+		d.printf("watchpoint %d: %s = % X\n", i, w.name, w.last)
+	}
+}
+
+func (d *Debugger) registers() {
+	d.c.WriteRegisters(d.out)
+}
+
+func (d *Debugger) set(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: set <reg> <value>")
+	}
+	v, err := strconv.ParseUint(args[1], 0, 16)
+	if err != nil {
+		return fmt.Errorf("invalid value %q: %v", args[1], err)
+	}
+
+	switch target := strings.ToUpper(args[0]); target {
+	case "PC":
+		d.c.PC = uint16(v)
+	case "I":
+		d.c.I = uint16(v)
+	case "SP":
+		if v > uint64(SPInit) {
+			return fmt.Errorf("SP must be at most %d", SPInit)
+		}
+		d.c.SP = uint8(v)
+	case "DT":
+		return d.c.DT.Set(uint(v))
+	case "ST":
+		return d.c.ST.Set(uint(v))
+	default:
+		reg, err := parseRegister(target)
+		if err != nil {
+			return err
+		}
+		if v > 0xFF {
+			return fmt.Errorf("value for %s must fit in a byte: %d", target, v)
+		}
+		d.c.V[reg] = uint8(v)
+	}
+	return nil
+}
+
+func (d *Debugger) memory(addr uint16, n int) error {
+	data, err := d.readMemory(addr, n)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(data); i += 16 {
+		end := i + 16
+		if end > len(data) {
+			end = len(data)
+		}
+		d.printf("0x%03X: % X\n", int(addr)+i, data[i:end])
+	}
+	return nil
+}
+
+func (d *Debugger) readMemory(addr uint16, n int) ([]byte, error) {
+	var data []byte
+	for i := 0; i < n; i++ {
+		b, err := d.c.Memory.Read(addr + uint16(i))
+		if err != nil {
+			return nil, fmt.Errorf("could not read memory: %v", err)
+		}
+		data = append(data, b)
+	}
+	return data, nil
+}
+
+func (d *Debugger) poke(args []string) error {
+	addr, err := requiredNumber(args, 0, "address")
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("missing bytes to write")
+	}
+	for i, a := range args[1:] {
+		b, err := strconv.ParseUint(a, 0, 8)
+		if err != nil {
+			return fmt.Errorf("invalid byte %q: %v", a, err)
+		}
+		err = d.c.Memory.Write(byte(b), uint16(addr)+uint16(i))
+		if err != nil {
+			return fmt.Errorf("could not write memory: %v", err)
+		}
+	}
+	return nil
+}
+
+//disassemble prints n instructions starting at addr.
+func (d *Debugger) disassemble(addr uint16, n int) {
+	a := addr
+	for i := 0; i < n; i++ {
+		inst, err := d.word(a)
+		if err != nil {
+			return
+		}
+
+		marker := "  "
+		if a == d.c.PC {
+			marker = "=>"
+		}
+		if d.breakpoints[a] {
+			marker = "*" + marker[1:]
+		}
+
+		desc := "(invalid)"
+		size := 2
+		if op, ok := LookupOpcode(inst); ok {
+			desc = op.Format(inst)
+			if op.Size() == 4 {
+				long, err := d.word(a + 2)
+				if err != nil {
+					return
+				}
+				desc = op.FormatLong(inst, long)
+				size = 4
+			}
+		}
+		d.printf("%s 0x%03X: %04X  %s\n", marker, a, inst, desc)
+		a += uint16(size)
+	}
+}
+
+//word reads the big endian 16 bit word at addr.
+func (d *Debugger) word(addr uint16) (uint16, error) {
+	hi, err := d.c.Memory.Read(addr)
+	if err != nil {
+		return 0, err
+	}
+	lo, err := d.c.Memory.Read(addr + 1)
+	if err != nil {
+		return 0, err
+	}
+	return uint16(hi)<<8 | uint16(lo), nil
+}
+
+func (d *Debugger) printf(format string, a ...interface{}) {
+	fmt.Fprintf(d.out, format, a...)
+}
+
+//parseRegister turns V0-VF into the register number.
+func parseRegister(s string) (uint8, error) {
+	s = strings.ToUpper(s)
+	if len(s) != 2 || s[0] != 'V' {
+		return 0, fmt.Errorf("unknown register %q", s)
+	}
+	r, err := strconv.ParseUint(s[1:], 16, 8)
+	if err != nil {
+		return 0, fmt.Errorf("unknown register %q", s)
+	}
+	return uint8(r), nil
+}
+
+//requiredNumber parses a 16 bit argument, like an address.
+func requiredNumber(args []string, i int, name string) (uint64, error) {
+	return parseNumber(args, i, name, 16)
+}
+
+//optionalCount parses a count of instructions or bytes, which can be bigger than 16 bits.
+func optionalCount(args []string, i int, def uint64) (uint64, error) {
+	if i >= len(args) {
+		return def, nil
+	}
+	return parseNumber(args, i, "count", 32)
+}
+
+func parseNumber(args []string, i int, name string, bits int) (uint64, error) {
+	if i >= len(args) {
+		return 0, fmt.Errorf("missing %s", name)
+	}
+	n, err := strconv.ParseUint(args[i], 0, bits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", name, args[i], err)
+	}
+	return n, nil
+}
+
+func optionalNumber(args []string, i int, def uint64) (uint64, error) {
+	if i >= len(args) {
+		return def, nil
+	}
+	return requiredNumber(args, i, "number")
+}
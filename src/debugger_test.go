@@ -0,0 +1,119 @@
+package chip8
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDebugger(t *testing.T) {
+	//LD V0, 1; ADD V0, 1; LD I, 0x300; LD [I], V0; JP 0x202
+	program := []byte{0x60, 0x01, 0x70, 0x01, 0xA3, 0x00, 0xF0, 0x55, 0x12, 0x02}
+
+	tt := []struct {
+		name     string
+		commands []string
+		pc       uint16
+		reg      treg
+		output   []string
+	}{
+		{name: "Step", commands: []string{"step 2"}, pc: 0x204, reg: treg{reg: 0x0, value: 2},
+			output: []string{"=> 0x204: A300  LD I, 0x300"}},
+		{name: "Continue to breakpoint", commands: []string{"break 0x206", "continue"}, pc: 0x206, reg: treg{reg: 0x0, value: 2},
+			output: []string{"breakpoint at 0x206"}},
+		{name: "Continue past breakpoint", commands: []string{"b 0x202", "c", "c"}, pc: 0x202, reg: treg{reg: 0x0, value: 2}},
+		{name: "Watch register", commands: []string{"step", "watch V0", "continue 100"}, pc: 0x204, reg: treg{reg: 0x0, value: 2},
+			output: []string{"watchpoint 0: V0 changed 01 -> 02"}},
+		{name: "Watch memory", commands: []string{"watch mem 0x300", "c 100"}, pc: 0x208, reg: treg{reg: 0x0, value: 2},
+			output: []string{"watchpoint 0: mem[0x300:0x301] changed 00 -> 02"}},
+		{name: "Set register", commands: []string{"set V3 0x2A", "set pc 0x204", "regs"}, pc: 0x204, reg: treg{reg: 0x3, value: 0x2A},
+			output: []string{"V3: 0x2A"}},
+		{name: "Memory", commands: []string{"poke 0x300 0xDE 0xAD", "mem 0x300 2"}, pc: PCInit,
+			output: []string{"0x300: DE AD"}},
+		{name: "Disassemble", commands: []string{"dis 0x200 2"}, pc: PCInit,
+			output: []string{"=> 0x200: 6001  LD V0, 0x01", "   0x202: 7001  ADD V0, 0x01"}},
+		{name: "Step 0", commands: []string{"step 0"}, pc: PCInit,
+			output: []string{"error: step count must be at least 1"}},
+		{name: "Continue past 16 bits", commands: []string{"continue 100000"}, pc: 0x208, reg: treg{reg: 0x0, value: 0xA9}},
+		{name: "Bad command", commands: []string{"frobnicate", "set V0"}, pc: PCInit,
+			output: []string{"error: unknown command", "error: usage: set"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			c8.LoadProgram(program)
+			in := strings.NewReader(strings.Join(tc.commands, "\n") + "\n")
+			var out bytes.Buffer
+
+			err := NewDebugger(c8, in, &out).Run()
+			if err != nil {
+				t.Fatalf("debugger failed: %v", err)
+			}
+			if c8.PC != tc.pc {
+				t.Errorf("PC - expected: %x; got: %x", tc.pc, c8.PC)
+			}
+			if c8.V[tc.reg.reg] != tc.reg.value {
+				t.Errorf("V[%x] - expected: %x; got: %x", tc.reg.reg, tc.reg.value, c8.V[tc.reg.reg])
+			}
+			for _, o := range tc.output {
+				if !strings.Contains(out.String(), o) {
+					t.Errorf("expected output to contain %q; got:\n%s", o, out.String())
+				}
+			}
+		})
+	}
+}
+
+//closingDisplay is a Framebuffer whose window is closed after a few frames.
+type closingDisplay struct {
+	*Framebuffer
+	frames int
+}
+
+func (d *closingDisplay) PollEvents() bool {
+	d.frames--
+	return d.frames > 0
+}
+
+//An endless loop can be stopped with Ctrl+C or by closing the window.
+func TestDebuggerStopContinue(t *testing.T) {
+	//JP 0x200
+	program := []byte{0x12, 0x00}
+
+	t.Run("Interrupt", func(t *testing.T) {
+		c8 := setup()
+		c8.LoadProgram(program)
+		var out bytes.Buffer
+		d := NewDebugger(c8, strings.NewReader(""), &out)
+		d.interrupt <- os.Interrupt
+
+		err := d.Exec("continue", nil)
+		if err != nil {
+			t.Fatalf("continue failed: %v", err)
+		}
+		if !strings.Contains(out.String(), "interrupted at 0x200") {
+			t.Errorf("expected to be interrupted; got:\n%s", out.String())
+		}
+	})
+
+	t.Run("Window closed", func(t *testing.T) {
+		c8 := setup()
+		g := &closingDisplay{Framebuffer: c8.G.(*Framebuffer), frames: 3}
+		c8.G = g
+		c8.LoadProgram(program)
+		var out bytes.Buffer
+
+		err := NewDebugger(c8, strings.NewReader(""), &out).Exec("c", nil)
+		if err != nil {
+			t.Fatalf("continue failed: %v", err)
+		}
+		if !strings.Contains(out.String(), "window closed") {
+			t.Errorf("expected the window closing to stop it; got:\n%s", out.String())
+		}
+		if c8.Cycles != uint64(3*c8.InstructionsPerFrame()) {
+			t.Errorf("expected to stop after 3 frames; got %d cycles", c8.Cycles)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+//Package disasm turns CHIP-8 programs back into assembly listings.
+package disasm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	chip8 "github.com/Neffats/Chip8/src"
+)
+
+//Line is a single line of a listing.
+type Line struct {
+	Addr  uint16
+	Bytes []byte
+	//Text is the assembly, unknown instructions and trailing bytes are written as db data.
+	Text string
+}
+
+//String formats the line as "0x200: 6A 02  LD VA, 0x02".
+func (l Line) String() string {
+	return fmt.Sprintf("0x%03X: %-5s  %s", l.Addr, fmt.Sprintf("% X", l.Bytes), l.Text)
+}
+
+//Disassemble decodes the program two bytes at a time, or four for XO-CHIP's long instructions,
+//starting at the given origin address.
+func Disassemble(program []byte, origin uint16) []Line {
+	var lines []Line
+	for i := 0; i < len(program); {
+		addr := origin + uint16(i)
+		if i+1 >= len(program) {
+			lines = append(lines, Line{Addr: addr, Bytes: program[i:], Text: data(program[i:])})
+			break
+		}
+
+		inst := uint16(program[i])<<8 | uint16(program[i+1])
+		size := 2
+		text := data(program[i : i+2])
+		if op, ok := chip8.LookupOpcode(inst); ok {
+			text = op.Format(inst)
+			if op.Size() == 4 && i+3 < len(program) {
+				size = 4
+				text = op.FormatLong(inst, uint16(program[i+2])<<8|uint16(program[i+3]))
+			} else if op.Size() == 4 {
+				//Truncated, the long operand is missing.
+				text = data(program[i : i+2])
+			}
+		}
+		lines = append(lines, Line{Addr: addr, Bytes: program[i : i+size], Text: text})
+		i += size
+	}
+	return lines
+}
+
+//Listing writes the disassembly of a program loaded at chip8.PCInit.
+func Listing(w io.Writer, program []byte) error {
+	for _, l := range Disassemble(program, chip8.PCInit) {
+		_, err := fmt.Fprintln(w, l)
+		if err != nil {
+			return fmt.Errorf("could not write listing: %v", err)
+		}
+	}
+	return nil
+}
+
+func data(b []byte) string {
+	bytes := make([]string, len(b))
+	for i := range b {
+		bytes[i] = fmt.Sprintf("0x%02X", b[i])
+	}
+	return "db " + strings.Join(bytes, ", ")
+}
@@ -0,0 +1,60 @@
+package disasm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDisassemble(t *testing.T) {
+	tt := []struct {
+		name     string
+		program  []byte
+		expected []string
+	}{
+		{name: "Load and draw", program: []byte{0x61, 0x20, 0xD0, 0x15},
+			expected: []string{"LD V1, 0x20", "DRW V0, V1, 5"}},
+		{name: "Flow control", program: []byte{0x00, 0xE0, 0x12, 0x34, 0x2A, 0xBC, 0x00, 0xEE, 0xB2, 0x00},
+			expected: []string{"CLS", "JP 0x234", "CALL 0xABC", "RET", "JP V0, 0x200"}},
+		{name: "Registers", program: []byte{0x8A, 0xB4, 0x8A, 0xB6, 0x3C, 0xFF, 0x9C, 0xD0},
+			expected: []string{"ADD VA, VB", "SHR VA, VB", "SE VC, 0xFF", "SNE VC, VD"}},
+		{name: "Fx instructions", program: []byte{0xF3, 0x07, 0xF3, 0x0A, 0xF3, 0x18, 0xF3, 0x29, 0xF3, 0x55, 0xF3, 0x65},
+			expected: []string{"LD V3, DT", "LD V3, K", "LD ST, V3", "LD F, V3", "LD [I], V3", "LD V3, [I]"}},
+		{name: "Unknown instruction and odd byte", program: []byte{0x51, 0x21, 0x07},
+			expected: []string{"db 0x51, 0x21", "db 0x07"}},
+		{name: "Super and XO instructions", program: []byte{0x00, 0xFF, 0x00, 0xC4, 0xF0, 0x00, 0x12, 0x34, 0xF2, 0x01, 0x51, 0x22},
+			expected: []string{"HIGH", "SCD 4", "LD I, long 0x1234", "PLANE 2", "SAVE V1, V2"}},
+		{name: "Truncated long instruction", program: []byte{0xF0, 0x00, 0x12},
+			expected: []string{"db 0xF0, 0x00", "db 0x12"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			lines := Disassemble(tc.program, 0x200)
+			if len(lines) != len(tc.expected) {
+				t.Fatalf("expected %d lines; got: %v", len(tc.expected), lines)
+			}
+			addr := uint16(0x200)
+			for i, l := range lines {
+				if l.Text != tc.expected[i] {
+					t.Errorf("line %d - expected: %q; got: %q", i, tc.expected[i], l.Text)
+				}
+				if l.Addr != addr {
+					t.Errorf("line %d - expected address: %x; got: %x", i, addr, l.Addr)
+				}
+				addr += uint16(len(l.Bytes))
+			}
+		})
+	}
+}
+
+func TestListing(t *testing.T) {
+	var out bytes.Buffer
+	err := Listing(&out, []byte{0x6A, 0x02, 0xA2, 0x0A})
+	if err != nil {
+		t.Fatalf("failed to write listing: %v", err)
+	}
+	expected := "0x200: 6A 02  LD VA, 0x02\n0x202: A2 0A  LD I, 0x20A\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
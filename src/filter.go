@@ -0,0 +1,157 @@
+package chip8
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+//Filter blends each frame with the ones before it before it's shown.
+//Most games erase a sprite and draw it again to move it, which flickers without one.
+type Filter interface {
+	//Apply takes the next frame and returns the image to show, one image pixel per screen pixel.
+	Apply(frame Frame, p Palette) *image.RGBA
+}
+
+//Names of the filters for NewFilter.
+const (
+	//FilterNone shows every frame as it is.
+	FilterNone = "none"
+	//FilterDecay fades pixels out after they're switched off.
+	FilterDecay = "decay"
+	//FilterOr lights every pixel lit in any of the last few frames.
+	FilterOr = "or"
+)
+
+//DefaultFilterStrength is how many frames a pixel stays on screen after it's switched off.
+const DefaultFilterStrength = 2
+
+//NewFilter returns the named filter, keeping pixels on screen for strength frames after they're switched off.
+//FilterNone returns a nil Filter.
+func NewFilter(name string, strength int) (Filter, error) {
+	if strength < 1 {
+		return nil, fmt.Errorf("filter strength must be positive, got %d", strength)
+	}
+	switch strings.ToLower(name) {
+	case FilterNone:
+		return nil, nil
+	case FilterDecay:
+		return NewDecayFilter(strength), nil
+	case FilterOr:
+		return NewOrFilter(strength), nil
+	}
+	return nil, fmt.Errorf("unknown filter %q, expected one of: %s", name, strings.Join(FilterNames(), ", "))
+}
+
+//FilterNames returns the names accepted by NewFilter.
+func FilterNames() []string {
+	return []string{FilterNone, FilterDecay, FilterOr}
+}
+
+//Render draws a frame in the palette's colours without any filtering.
+func Render(frame Frame, p Palette) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, frame.W, frame.H))
+	for i, v := range frame.Pix {
+		img.SetRGBA(i%frame.W, i/frame.W, p[v])
+	}
+	return img
+}
+
+//FilterTap is a FrameTap that runs every frame through Filter, keeping the latest image
+//so the filtered screen can be saved without a window.
+type FilterTap struct {
+	Filter  Filter
+	Palette Palette
+	//Image is the latest filtered frame, nil until the first one.
+	Image *image.RGBA
+}
+
+//TapFrame runs the frame through the filter.
+func (t *FilterTap) TapFrame(n uint64, frame Frame) error {
+	t.Image = t.Filter.Apply(frame, t.Palette)
+	return nil
+}
+
+//DecayFilter fades pixels out over Strength frames once they're switched off, like a phosphor screen.
+type DecayFilter struct {
+	Strength int
+
+	//level counts down the frames left before each pixel has faded out.
+	level []int
+	//last is the value each pixel had when it was last lit, so it fades in the right colour.
+	last []uint8
+}
+
+//NewDecayFilter returns a filter that fades pixels out over strength frames.
+func NewDecayFilter(strength int) *DecayFilter {
+	return &DecayFilter{Strength: strength}
+}
+
+//Apply fades the pixels switched off in the frame and returns the blended image.
+func (f *DecayFilter) Apply(frame Frame, p Palette) *image.RGBA {
+	if len(f.level) != len(frame.Pix) {
+		f.level = make([]int, len(frame.Pix))
+		f.last = make([]uint8, len(frame.Pix))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, frame.W, frame.H))
+	for i, v := range frame.Pix {
+		c := p[v]
+		if v != 0 {
+			f.level[i] = f.Strength
+			f.last[i] = v
+		} else if f.level[i] > 0 {
+			c = blend(p[0], p[f.last[i]], f.level[i], f.Strength+1)
+			f.level[i]--
+		}
+		img.SetRGBA(i%frame.W, i/frame.W, c)
+	}
+	return img
+}
+
+//blend mixes n/d of fg into bg.
+func blend(bg color.RGBA, fg color.RGBA, n int, d int) color.RGBA {
+	mix := func(b uint8, f uint8) uint8 {
+		return uint8((int(b)*(d-n) + int(f)*n) / d)
+	}
+	return color.RGBA{R: mix(bg.R, fg.R), G: mix(bg.G, fg.G), B: mix(bg.B, fg.B), A: mix(bg.A, fg.A)}
+}
+
+//OrFilter lights each pixel that was lit in any of the last Strength+1 frames.
+//Sharper than DecayFilter, but moving sprites leave a trail at full brightness.
+type OrFilter struct {
+	Strength int
+
+	//history holds the last frames' pixels, next is the oldest and gets overwritten next.
+	history [][]uint8
+	next    int
+}
+
+//NewOrFilter returns a filter that keeps pixels lit for strength frames after they're switched off.
+func NewOrFilter(strength int) *OrFilter {
+	return &OrFilter{Strength: strength}
+}
+
+//Apply adds the frame to the history and returns the combined image.
+func (f *OrFilter) Apply(frame Frame, p Palette) *image.RGBA {
+	if len(f.history) != f.Strength+1 || len(f.history[0]) != len(frame.Pix) {
+		f.history = make([][]uint8, f.Strength+1)
+		for i := range f.history {
+			f.history[i] = make([]uint8, len(frame.Pix))
+		}
+		f.next = 0
+	}
+	copy(f.history[f.next], frame.Pix)
+	f.next = (f.next + 1) % len(f.history)
+
+	img := image.NewRGBA(image.Rect(0, 0, frame.W, frame.H))
+	for i := range frame.Pix {
+		var v uint8
+		for _, pix := range f.history {
+			v |= pix[i]
+		}
+		img.SetRGBA(i%frame.W, i/frame.W, p[v])
+	}
+	return img
+}
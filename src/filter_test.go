@@ -0,0 +1,115 @@
+package chip8
+
+import (
+	"image/color"
+	"testing"
+)
+
+//frames returns single row frames with the given pixel values, one frame per string.
+func frames(rows ...string) []Frame {
+	var fs []Frame
+	for _, row := range rows {
+		f := Frame{W: len(row), H: 1, Pix: make([]uint8, len(row))}
+		for i, c := range row {
+			f.Pix[i] = uint8(c - '0')
+		}
+		fs = append(fs, f)
+	}
+	return fs
+}
+
+func TestDecayFilter(t *testing.T) {
+	p := Palette{rgb(0x000000), rgb(0xFFFFFF), rgb(0x00FF00), rgb(0x0000FF)}
+	f := NewDecayFilter(2)
+
+	tt := []struct {
+		name     string
+		frame    Frame
+		expected []color.RGBA
+	}{
+		{name: "Lit", expected: []color.RGBA{rgb(0xFFFFFF), rgb(0x00FF00), rgb(0x000000)}},
+		{name: "Switched off", expected: []color.RGBA{rgb(0xAAAAAA), rgb(0x00AA00), rgb(0x000000)}},
+		{name: "Fading", expected: []color.RGBA{rgb(0x555555), rgb(0x005500), rgb(0x0000FF)}},
+		{name: "Faded", expected: []color.RGBA{rgb(0x000000), rgb(0x000000), rgb(0x0000AA)}},
+	}
+	for i, frame := range frames("120", "000", "003", "000") {
+		tt[i].frame = frame
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			img := f.Apply(tc.frame, p)
+			for x, expected := range tc.expected {
+				if got := img.RGBAAt(x, 0); got != expected {
+					t.Errorf("pixel %d - expected: %v; got: %v", x, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestOrFilter(t *testing.T) {
+	p := Palette{rgb(0x000000), rgb(0xFFFFFF), rgb(0x00FF00), rgb(0x0000FF)}
+	f := NewOrFilter(1)
+
+	tt := []struct {
+		name     string
+		frame    Frame
+		expected []color.RGBA
+	}{
+		{name: "Lit", expected: []color.RGBA{rgb(0xFFFFFF), rgb(0x000000)}},
+		{name: "Moved", expected: []color.RGBA{rgb(0xFFFFFF), rgb(0xFFFFFF)}},
+		{name: "Other plane", expected: []color.RGBA{rgb(0x000000), rgb(0x0000FF)}},
+		{name: "Resized", expected: []color.RGBA{rgb(0x000000), rgb(0x000000), rgb(0xFFFFFF)}},
+	}
+	for i, frame := range frames("10", "01", "02", "001") {
+		tt[i].frame = frame
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			img := f.Apply(tc.frame, p)
+			for x, expected := range tc.expected {
+				if got := img.RGBAAt(x, 0); got != expected {
+					t.Errorf("pixel %d - expected: %v; got: %v", x, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewFilter(t *testing.T) {
+	f, err := NewFilter("none", DefaultFilterStrength)
+	if err != nil || f != nil {
+		t.Errorf("expected no filter; got: %v, %v", f, err)
+	}
+	f, err = NewFilter("Decay", 3)
+	if d, ok := f.(*DecayFilter); err != nil || !ok || d.Strength != 3 {
+		t.Errorf("expected a decay filter of strength 3; got: %v, %v", f, err)
+	}
+	_, err = NewFilter("or", 0)
+	if err == nil {
+		t.Errorf("expected an error for a strength of 0")
+	}
+	_, err = NewFilter("blur", 1)
+	if err == nil {
+		t.Errorf("expected an error for an unknown filter")
+	}
+}
+
+func TestFilterTap(t *testing.T) {
+	p := palettes[DefaultPalette]
+	tap := &FilterTap{Filter: NewOrFilter(1), Palette: p}
+	lit := testFrame()
+	err := tap.TapFrame(1, lit)
+	if err != nil {
+		t.Fatalf("failed to tap frame: %v", err)
+	}
+	err = tap.TapFrame(2, Frame{W: lit.W, H: lit.H, Pix: make([]uint8, len(lit.Pix))})
+	if err != nil {
+		t.Fatalf("failed to tap frame: %v", err)
+	}
+	if tap.Image == nil || tap.Image.RGBAAt(0, 0) != p[1] {
+		t.Errorf("expected the pixel lit the frame before to stay lit")
+	}
+}
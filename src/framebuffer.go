@@ -0,0 +1,292 @@
+package chip8
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	//ScreenWidth is the width of the screen.
+	ScreenWidth = 64
+	//ScreenHeight is the height of the screen.
+	ScreenHeight = 32
+
+	//HiResWidth is the width of the SUPER-CHIP hi-res screen.
+	HiResWidth = 128
+	//HiResHeight is the height of the SUPER-CHIP hi-res screen.
+	HiResHeight = 64
+)
+
+//Framebuffer is an in-memory Display, it holds the screen state without drawing it anywhere.
+//Used for headless runs and tests, and embedded by the SDL Graphics to share the drawing logic.
+type Framebuffer struct {
+	m *Memory
+
+	//Sized for hi-res, w and h are the current resolution.
+	//Each pixel holds one bit per plane, only XO-CHIP programs use the second plane.
+	screen    [HiResWidth][HiResHeight]uint8
+	screenmux sync.RWMutex
+
+	w, h int32
+
+	//planes is the bit mask of planes drawn to and cleared.
+	planes uint8
+	//clip cuts sprites off at the screen edge instead of wrapping them.
+	clip bool
+}
+
+//NewFramebuffer returns a new blank framebuffer.
+func NewFramebuffer(mem *Memory) *Framebuffer {
+	return &Framebuffer{
+		m:      mem,
+		w:      ScreenWidth,
+		h:      ScreenHeight,
+		planes: 1,
+	}
+}
+
+//Init does nothing, there is no window to set up.
+func (f *Framebuffer) Init() error {
+	return nil
+}
+
+//PaintSurface does nothing, there is no window to paint.
+func (f *Framebuffer) PaintSurface() error {
+	return nil
+}
+
+//Destroy does nothing, there is no window to destroy.
+func (f *Framebuffer) Destroy() {}
+
+//Pixel returns the value of the pixel at (x, y), bit 0 set if lit in plane 1 and bit 1 in plane 2.
+func (f *Framebuffer) Pixel(x int32, y int32) (uint8, error) {
+	if x < 0 || x >= f.w || y < 0 || y >= f.h {
+		return 0, fmt.Errorf("pixel out of bounds: (%d, %d)", x, y)
+	}
+	f.screenmux.RLock()
+	defer f.screenmux.RUnlock()
+
+	return f.screen[x][y], nil
+}
+
+//Frame returns a copy of the screen.
+func (f *Framebuffer) Frame() Frame {
+	f.screenmux.RLock()
+	defer f.screenmux.RUnlock()
+
+	frame := Frame{W: int(f.w), H: int(f.h), Pix: make([]uint8, f.w*f.h)}
+	for y := int32(0); y < f.h; y++ {
+		for x := int32(0); x < f.w; x++ {
+			frame.Pix[y*f.w+x] = f.screen[x][y]
+		}
+	}
+	return frame
+}
+
+//SetFrame overwrites the screen with a copy taken by Frame, switching resolution to match.
+func (f *Framebuffer) SetFrame(frame Frame) error {
+	lores := frame.W == ScreenWidth && frame.H == ScreenHeight
+	hires := frame.W == HiResWidth && frame.H == HiResHeight
+	if !lores && !hires || len(frame.Pix) != frame.W*frame.H {
+		return fmt.Errorf("frame is %dx%d with %d pixels, expected %dx%d or %dx%d",
+			frame.W, frame.H, len(frame.Pix), ScreenWidth, ScreenHeight, HiResWidth, HiResHeight)
+	}
+	f.screenmux.Lock()
+	defer f.screenmux.Unlock()
+
+	f.w, f.h = int32(frame.W), int32(frame.H)
+
+	for y := int32(0); y < f.h; y++ {
+		for x := int32(0); x < f.w; x++ {
+			f.screen[x][y] = frame.Pix[y*f.w+x]
+		}
+	}
+	return nil
+}
+
+//SetClip picks between sprites being cut off at the screen edge or wrapping around.
+//Either way the sprite's starting position wraps.
+func (f *Framebuffer) SetClip(on bool) {
+	f.screenmux.Lock()
+	defer f.screenmux.Unlock()
+	f.clip = on
+}
+
+//Draw sprites onto the screen.
+//Each selected plane takes the next n bytes of sprite data, starting with plane 1.
+func (f *Framebuffer) Draw(x int32, y int32, n uint8, addr uint16) (bool, error) {
+	a := addr
+	if f.m.Size() == MemorySize {
+		a &= 0x0FFF
+	}
+
+	f.screenmux.Lock()
+	defer f.screenmux.Unlock()
+
+	x, y = x%f.w, y%f.h
+	collision := false
+	for _, plane := range f.selected() {
+		//Read the sprite data from memory.
+		var sprite []uint8
+		for i := 0; i < int(n); i++ {
+			spriteline, err := f.m.Read(a)
+			if err != nil {
+				return false, fmt.Errorf("could not read sprite data: %v", err)
+			}
+			sprite = append(sprite, uint8(spriteline))
+			a++
+		}
+
+		for screeny := 0; screeny < len(sprite); screeny++ {
+			//Sprites are always 8 pixels wide, one bit per pixel.
+			for screenx := 0; screenx < 8; screenx++ {
+				if f.flip(x+int32(screenx), y+int32(screeny), sprite[screeny]>>uint8(7-screenx)&0x01, plane) {
+					collision = true
+				}
+			}
+		}
+	}
+
+	return collision, nil
+}
+
+//DrawLarge draws a 16x16 sprite, two bytes per row, onto the screen.
+//Each selected plane takes the next 32 bytes of sprite data, starting with plane 1.
+func (f *Framebuffer) DrawLarge(x int32, y int32, addr uint16) (bool, error) {
+	f.screenmux.Lock()
+	defer f.screenmux.Unlock()
+
+	x, y = x%f.w, y%f.h
+	a := addr
+	collision := false
+	for _, plane := range f.selected() {
+		var sprite [16]uint16
+		for i := 0; i < len(sprite); i++ {
+			hi, err := f.m.Read(a)
+			if err != nil {
+				return false, fmt.Errorf("could not read sprite data: %v", err)
+			}
+			lo, err := f.m.Read(a + 1)
+			if err != nil {
+				return false, fmt.Errorf("could not read sprite data: %v", err)
+			}
+			sprite[i] = uint16(hi)<<8 | uint16(lo)
+			a += 2
+		}
+
+		for screeny := 0; screeny < 16; screeny++ {
+			for screenx := 0; screenx < 16; screenx++ {
+				if f.flip(x+int32(screenx), y+int32(screeny), uint8(sprite[screeny]>>uint(15-screenx))&0x01, plane) {
+					collision = true
+				}
+			}
+		}
+	}
+
+	return collision, nil
+}
+
+//flip xors a sprite pixel into a plane, wrapping around or clipped at the edges.
+//Returns true if a lit pixel got switched off. Must be called with the lock held.
+func (f *Framebuffer) flip(x int32, y int32, pixel uint8, plane uint8) bool {
+	if pixel == 0 {
+		return false
+	}
+	if f.clip && (x >= f.w || y >= f.h) {
+		return false
+	}
+	px := x % f.w
+	py := y % f.h
+
+	erased := f.screen[px][py]&plane != 0
+	f.screen[px][py] ^= plane
+	return erased
+}
+
+//selected returns the bit of each plane in the mask, in drawing order.
+func (f *Framebuffer) selected() []uint8 {
+	var planes []uint8
+	for _, plane := range []uint8{1, 2} {
+		if f.planes&plane != 0 {
+			planes = append(planes, plane)
+		}
+	}
+	return planes
+}
+
+//Planes returns the bit mask of planes drawn to and cleared.
+func (f *Framebuffer) Planes() uint8 {
+	f.screenmux.RLock()
+	defer f.screenmux.RUnlock()
+	return f.planes
+}
+
+//SetPlanes selects the planes drawn to and cleared, bit 0 for plane 1 and bit 1 for plane 2.
+func (f *Framebuffer) SetPlanes(mask uint8) error {
+	if mask > 3 {
+		return fmt.Errorf("plane mask out of bounds: %x", mask)
+	}
+	f.screenmux.Lock()
+	defer f.screenmux.Unlock()
+	f.planes = mask
+	return nil
+}
+
+//Scroll moves the selected planes by dx pixels right and dy pixels down, negative to go the other way.
+//Pixels scrolled off the edge are lost and the gap left behind is blank.
+func (f *Framebuffer) Scroll(dx int32, dy int32) error {
+	f.screenmux.Lock()
+	defer f.screenmux.Unlock()
+
+	var scrolled [HiResWidth][HiResHeight]uint8
+	for x := int32(0); x < f.w; x++ {
+		for y := int32(0); y < f.h; y++ {
+			//Planes that aren't selected stay where they are.
+			scrolled[x][y] = f.screen[x][y] &^ f.planes
+			sx, sy := x-dx, y-dy
+			if sx < 0 || sx >= f.w || sy < 0 || sy >= f.h {
+				continue
+			}
+			scrolled[x][y] |= f.screen[sx][sy] & f.planes
+		}
+	}
+	f.screen = scrolled
+
+	return nil
+}
+
+//HighRes returns true if the screen is in the 128x64 mode.
+func (f *Framebuffer) HighRes() bool {
+	f.screenmux.RLock()
+	defer f.screenmux.RUnlock()
+	return f.w == HiResWidth
+}
+
+//SetHighRes switches between the 64x32 and 128x64 modes, clearing every plane.
+func (f *Framebuffer) SetHighRes(on bool) error {
+	f.screenmux.Lock()
+	defer f.screenmux.Unlock()
+
+	if on {
+		f.w, f.h = HiResWidth, HiResHeight
+	} else {
+		f.w, f.h = ScreenWidth, ScreenHeight
+	}
+	f.screen = [HiResWidth][HiResHeight]uint8{}
+
+	return nil
+}
+
+//ClearScreen zero's out every pixel of the selected planes.
+func (f *Framebuffer) ClearScreen() error {
+	f.screenmux.Lock()
+	defer f.screenmux.Unlock()
+
+	for x := int32(0); x < f.w; x++ {
+		for y := int32(0); y < f.h; y++ {
+			f.screen[x][y] &^= f.planes
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package chip8
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Neffats/Chip8/src/romdb"
+)
+
+var (
+	update     = flag.Bool("update", false, "Rewrite the golden frames in testdata/golden instead of checking against them.")
+	goldenROMs = flag.String("roms", "../examples", "Directory of ROMs checked by TestGoldenFrames.")
+)
+
+//goldenCycles is how long each ROM runs before its screen is checked.
+const goldenCycles = 3000
+
+//goldenDir holds the expected registers and screen of each ROM, with the screen as ASCII art,
+//plus an optional key script named after the ROM.
+var goldenDir = filepath.Join("testdata", "golden")
+
+//TestGoldenFrames runs every ROM for goldenCycles and checks the registers and screen match its golden file.
+//A ROM that stops with an error has the error checked too, so ROMs that crash can still be covered.
+//Run with -update to rewrite the golden files after a deliberate change.
+func TestGoldenFrames(t *testing.T) {
+	roms, err := filepath.Glob(filepath.Join(*goldenROMs, "*.ch8"))
+	if err != nil {
+		t.Fatalf("failed to list roms: %v", err)
+	}
+	if len(roms) == 0 {
+		t.Fatalf("no roms in %s", *goldenROMs)
+	}
+
+	for _, rom := range roms {
+		name := strings.TrimSuffix(filepath.Base(rom), filepath.Ext(rom))
+		t.Run(name, func(t *testing.T) {
+			c8, runErr := runGolden(t, rom, filepath.Join(goldenDir, name+".keys"))
+			var got bytes.Buffer
+			c8.WriteRegisters(&got)
+			if runErr != nil {
+				fmt.Fprintf(&got, "error: %v\n", runErr)
+			}
+			got.WriteString("\n")
+			err := WriteASCII(&got, c8.G.Frame())
+			if err != nil {
+				t.Fatalf("failed to write frame: %v", err)
+			}
+
+			golden := filepath.Join(goldenDir, name+".txt")
+			if *update {
+				err = os.WriteFile(golden, got.Bytes(), 0644)
+				if err != nil {
+					t.Fatalf("failed to update golden frame: %v", err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden frame, run with -update to create it: %v", err)
+			}
+			if !bytes.Equal(got.Bytes(), expected) {
+				t.Errorf("doesn't match %s:\n%s", golden, frameDiff(expected, got.Bytes()))
+			}
+		})
+	}
+}
+
+//runGolden runs the ROM headless with the settings from the ROM database and the key script, if there is one.
+//Returns the CPU once it's done and the error it stopped on, if any.
+func runGolden(t *testing.T, rom string, keys string) (*CPU, error) {
+	program, err := os.ReadFile(rom)
+	if err != nil {
+		t.Fatalf("failed to read rom: %v", err)
+	}
+
+	p := PlatformCHIP8
+	var q Quirks
+	ips := DefaultIPS
+	if entry, ok := romdb.Lookup(program); ok {
+		p, err = ParsePlatform(entry.Platform)
+		if err != nil {
+			t.Fatalf("bad platform in rom database: %v", err)
+		}
+		if entry.Quirks != "" {
+			q, err = LookupQuirks(entry.Quirks)
+			if err != nil {
+				t.Fatalf("bad quirks in rom database: %v", err)
+			}
+		}
+		if entry.IPS != 0 {
+			ips = entry.IPS
+		}
+	}
+
+	c8 := NewHeadlessCPU(q)
+	c8.IPS = ips
+	c8.SetPlatform(p)
+	c8.Seed(0)
+
+	script, err := os.ReadFile(keys)
+	if err == nil {
+		events, err := ParseKeyScript(bytes.NewReader(script))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", keys, err)
+		}
+		for _, e := range events {
+			c8.Input.(*VirtualKeypad).Schedule(e.Cycle, e.Key, e.Pressed)
+		}
+	} else if !os.IsNotExist(err) {
+		t.Fatalf("failed to read key script: %v", err)
+	}
+
+	err = c8.Init()
+	if err != nil {
+		t.Fatalf("failed to init: %v", err)
+	}
+	err = c8.LoadProgram(program)
+	if err != nil {
+		t.Fatalf("failed to load program: %v", err)
+	}
+	return c8, c8.RunUntil(goldenCycles)
+}
+
+//frameDiff shows the lines that differ between two golden files.
+func frameDiff(expected []byte, got []byte) string {
+	want := strings.Split(string(expected), "\n")
+	have := strings.Split(string(got), "\n")
+	var diff strings.Builder
+	for i := 0; i < len(want) || i < len(have); i++ {
+		var w, h string
+		if i < len(want) {
+			w = want[i]
+		}
+		if i < len(have) {
+			h = have[i]
+		}
+		if w != h {
+			fmt.Fprintf(&diff, "line %d\n  expected: %s\n  got:      %s\n", i+1, w, h)
+		}
+	}
+	return diff.String()
+}
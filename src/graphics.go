@@ -1,50 +1,112 @@
+//go:build !nosdl
+// +build !nosdl
+
 package chip8
 
 import (
 	"fmt"
+	"image"
 	"image/color"
-	"sync"
+	"strings"
 
 	"github.com/veandco/go-sdl2/sdl"
 )
 
-const (
-	//ScreenWidth is the width of the screen.
-	ScreenWidth = 64
-	//ScreenHeight is the height of the screen.
-	ScreenHeight = 32
-
-	//ScreenScale specifies how the screen will be scaled.
-	ScreenScale = 20
-)
+//ScreenScale is the starting size of a pixel in window pixels, the window can be resized after.
+const ScreenScale = 20
 
 //Graphics handles the window for the Chip8.
+//The screen state and drawing logic come from the embedded Framebuffer.
 type Graphics struct {
-	m *Memory
+	*Framebuffer
 
-	screen    [ScreenWidth][ScreenHeight]uint8
-	screenmux sync.RWMutex
+	window   *sdl.Window
+	renderer *sdl.Renderer
+	//texture holds the screen at its current resolution, recreated when the resolution changes.
+	texture *sdl.Texture
+	texw    int32
+	texh    int32
+	pixels  []uint32
 
-	window  *sdl.Window
-	surface *sdl.Surface
+	scale int32
 
-	w, h, scale int32
+	palette Palette
+	//paletteName is the named palette in use, empty once the colours have been customised.
+	paletteName string
 
-	fgColour color.Color
-	bgColour color.Color
+	//filter blends frames together before they're shown, nil to show them as they are.
+	filter Filter
 }
 
 //NewGraphics returns a new graphics struct with initialised values.
 func NewGraphics(mem *Memory) *Graphics {
 	return &Graphics{
-		m:     mem,
-		w:     ScreenWidth,
-		h:     ScreenHeight,
-		scale: ScreenScale,
+		Framebuffer: NewFramebuffer(mem),
+		scale:       ScreenScale,
+		palette:     palettes[DefaultPalette],
+		paletteName: DefaultPalette,
+	}
+}
+
+//SetScale sets the starting size of a pixel in window pixels, must be called before Init.
+func (g *Graphics) SetScale(scale int32) error {
+	if scale < 1 {
+		return fmt.Errorf("scale must be positive, got %d", scale)
+	}
+	g.scale = scale
+	return nil
+}
+
+//SetColours sets the colours of lit and unlit pixels, leaving the plane 2 colours alone.
+func (g *Graphics) SetColours(fg color.Color, bg color.Color) {
+	g.screenmux.Lock()
+	defer g.screenmux.Unlock()
+	g.palette[0] = color.RGBAModel.Convert(bg).(color.RGBA)
+	g.palette[1] = color.RGBAModel.Convert(fg).(color.RGBA)
+	g.paletteName = ""
+}
+
+//SetPalette switches to the named palette.
+func (g *Graphics) SetPalette(name string) error {
+	p, err := LookupPalette(name)
+	if err != nil {
+		return err
 	}
+	g.screenmux.Lock()
+	defer g.screenmux.Unlock()
+	g.palette = p
+	g.paletteName = strings.ToLower(name)
+	return nil
 }
 
-//Init initalises the sdl window.
+//Palette returns the colours in use.
+func (g *Graphics) Palette() Palette {
+	g.screenmux.RLock()
+	defer g.screenmux.RUnlock()
+	return g.palette
+}
+
+//SetFilter sets the filter applied to each frame before it's shown, nil for none.
+func (g *Graphics) SetFilter(f Filter) {
+	g.screenmux.Lock()
+	defer g.screenmux.Unlock()
+	g.filter = f
+}
+
+//CyclePalette switches to the next named palette in alphabetical order, returning its name.
+func (g *Graphics) CyclePalette() string {
+	names := PaletteNames()
+	next := names[0]
+	for i, name := range names {
+		if name == g.paletteName && i+1 < len(names) {
+			next = names[i+1]
+		}
+	}
+	g.SetPalette(next)
+	return next
+}
+
+//Init initalises the sdl window and the renderer that scales the screen up to fit it.
 func (g *Graphics) Init() error {
 	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
 		return fmt.Errorf("could not initialise sdl: %v", err)
@@ -53,14 +115,19 @@ func (g *Graphics) Init() error {
 	// Stop "non-name on left side of :=" error
 	var err error
 	g.window, err = sdl.CreateWindow("Chip8", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-		g.w*g.scale, g.h*g.scale, sdl.WINDOW_SHOWN)
+		ScreenWidth*g.scale, ScreenHeight*g.scale, sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE)
 	if err != nil {
 		return fmt.Errorf("could not create sdl window: %v", err)
 	}
 
-	g.surface, err = g.window.GetSurface()
+	g.renderer, err = sdl.CreateRenderer(g.window, -1, sdl.RENDERER_SOFTWARE)
 	if err != nil {
-		return fmt.Errorf("could not get sdl window surface: %v", err)
+		return fmt.Errorf("could not create sdl renderer: %v", err)
+	}
+	//Only scale by whole numbers so every pixel is the same size, the rest of the window is letterboxed.
+	err = g.renderer.SetIntegerScale(true)
+	if err != nil {
+		return fmt.Errorf("could not set integer scaling: %v", err)
 	}
 	return nil
 }
@@ -89,76 +156,145 @@ func (g *Graphics) Run() error {
 	return nil
 }
 
-//PaintSurface takes the screen array and translates it into pixels on the window surface.
-func (g *Graphics) PaintSurface() error {
-	g.screenmux.RLock()
-	defer g.screenmux.RUnlock()
-	for h := 0; h < int(g.h); h++ {
-		for w := 0; w < int(g.w); w++ {
-			if g.screen[w][h] == 1 {
-				pixel := sdl.Rect{X: int32(w) * g.scale, Y: int32(h) * g.scale, W: g.scale, H: g.scale}
-				g.surface.FillRect(&pixel, 0x0FFFFFFFF)
-			}
+//PollEvents handles pending window events, so the window keeps responding while the debugger runs.
+//Returns false if the window was closed.
+func (g *Graphics) PollEvents() bool {
+	open := true
+	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+		if _, ok := event.(*sdl.QuitEvent); ok {
+			open = false
 		}
 	}
-	g.window.UpdateSurface()
-
-	return nil
+	return open
 }
 
-//Draw sprites onto the screen.
-func (g *Graphics) Draw(x int32, y int32, n uint8, addr uint16) (bool, error) {
-	a := addr & 0x0FFF
-
-	var sprite []uint8
-	collision := false
+//PaintSurface uploads the screen to the texture and draws it scaled to the window.
+//The renderer keeps the aspect ratio when the window is resized or fullscreen.
+func (g *Graphics) PaintSurface() error {
+	g.screenmux.RLock()
+	w, h := g.w, g.h
+	filter := g.filter
+	palette := g.palette
+	if filter == nil {
+		g.pixels = g.argbPixels(g.pixels)
+	}
+	g.screenmux.RUnlock()
 
-	//Read the sprite data from memory.
-	for i := 0; i < int(n); i++ {
-		spriteline, err := g.m.Read(a + uint16(i))
+	if filter != nil {
+		frame := g.Frame()
+		w, h = int32(frame.W), int32(frame.H)
+		g.pixels = imagePixels(filter.Apply(frame, palette), g.pixels)
+	}
+	bg := palette[0]
 
+	if g.texture == nil || g.texw != w || g.texh != h {
+		err := g.resize(w, h)
 		if err != nil {
-			return false, fmt.Errorf("could not read sprite data: %v", err)
+			return err
 		}
+	}
 
-		sprite = append(sprite, uint8(spriteline))
+	err := g.texture.UpdateRGBA(nil, g.pixels, int(w))
+	if err != nil {
+		return fmt.Errorf("could not update texture: %v", err)
+	}
+	g.renderer.SetDrawColor(bg.R, bg.G, bg.B, bg.A)
+	g.renderer.Clear()
+	err = g.renderer.Copy(g.texture, nil, nil)
+	if err != nil {
+		return fmt.Errorf("could not copy texture: %v", err)
 	}
+	g.renderer.Present()
 
-	g.screenmux.Lock()
-	defer g.screenmux.Unlock()
-	for screeny := 0; screeny < len(sprite); screeny++ {
-		for screenx := 0; screenx < len(sprite); screenx++ {
-			pixel := (sprite[screeny] >> uint8((7 - screenx))) & 0x01
+	return nil
+}
 
-			//Check if any bits will get flipped.
-			if pixel != g.screen[(x+int32(screenx))%g.w][(y+int32(screeny))%g.h] {
-				collision = true
-			}
+//resize makes a new texture for a w x h screen and scales it to the window.
+func (g *Graphics) resize(w int32, h int32) error {
+	if g.texture != nil {
+		g.texture.Destroy()
+		g.texture = nil
+	}
+	texture, err := g.renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STREAMING, w, h)
+	if err != nil {
+		return fmt.Errorf("could not create texture: %v", err)
+	}
+	err = g.renderer.SetLogicalSize(w, h)
+	if err != nil {
+		texture.Destroy()
+		return fmt.Errorf("could not set logical size: %v", err)
+	}
+	g.texture, g.texw, g.texh = texture, w, h
+	return nil
+}
 
-			//Xor screen pixel with sprite pixel, modulo there for wrap around.
-			g.screen[(x+int32(screenx))%g.w][(y+int32(screeny))%g.h] ^= pixel
+//argbPixels fills buf with the screen in palette colours, one row after another, growing it if needed.
+//Must be called with the lock held.
+func (g *Graphics) argbPixels(buf []uint32) []uint32 {
+	n := int(g.w * g.h)
+	if cap(buf) < n {
+		buf = make([]uint32, n)
+	}
+	buf = buf[:n]
+
+	var colours [len(g.palette)]uint32
+	for i, c := range g.palette {
+		colours[i] = argb(c)
+	}
+	for y := int32(0); y < g.h; y++ {
+		for x := int32(0); x < g.w; x++ {
+			buf[y*g.w+x] = colours[g.screen[x][y]]
 		}
 	}
+	return buf
+}
 
-	return collision, nil
+//imagePixels fills buf with the image's pixels packed the way the texture stores them, growing it if needed.
+func imagePixels(img *image.RGBA, buf []uint32) []uint32 {
+	n := len(img.Pix) / 4
+	if cap(buf) < n {
+		buf = make([]uint32, n)
+	}
+	buf = buf[:n]
+	for i := range buf {
+		px := img.Pix[i*4 : i*4+4]
+		buf[i] = uint32(px[3])<<24 | uint32(px[0])<<16 | uint32(px[1])<<8 | uint32(px[2])
+	}
+	return buf
 }
 
-//ClearScreen zero's out every pixel on the screen.
-func (g *Graphics) ClearScreen() error {
-	g.screenmux.Lock()
-	defer g.screenmux.Unlock()
+//argb packs a colour the way the texture stores it.
+func argb(c color.Color) uint32 {
+	r, g, b, a := c.RGBA()
+	return (a>>8)<<24 | (r>>8)<<16 | (g>>8)<<8 | b>>8
+}
 
-	for y := int32(0); ScreenHeight < g.h; y++ {
-		for x := int32(0); ScreenWidth < g.w; x++ {
-			g.screen[y][x] = 0
-		}
+//ToggleFullscreen switches between the window and fullscreen at the desktop resolution.
+//Returns true if now fullscreen.
+func (g *Graphics) ToggleFullscreen() (bool, error) {
+	if g.window == nil {
+		return false, fmt.Errorf("window not initialised")
 	}
-
-	return nil
+	fullscreen := g.window.GetFlags()&sdl.WINDOW_FULLSCREEN_DESKTOP != sdl.WINDOW_FULLSCREEN_DESKTOP
+	var flags uint32
+	if fullscreen {
+		flags = sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+	err := g.window.SetFullscreen(flags)
+	if err != nil {
+		return false, fmt.Errorf("could not switch fullscreen: %v", err)
+	}
+	return fullscreen, nil
 }
 
 //Destroy the graphics window.
 func (g *Graphics) Destroy() {
-	sdl.Quit()
+	if g.texture != nil {
+		g.texture.Destroy()
+	}
+	if g.renderer != nil {
+		g.renderer.Destroy()
+	}
 	g.window.Destroy()
+	sdl.Quit()
 }
@@ -0,0 +1,86 @@
+//go:build !nosdl
+// +build !nosdl
+
+package chip8
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestArgbPixels(t *testing.T) {
+	m := Memory{}
+	g := NewGraphics(&m)
+	g.SetPalette("octo")
+	g.screen[1][0] = 1
+	g.screen[0][1] = 3
+
+	pixels := g.argbPixels(nil)
+	if len(pixels) != ScreenWidth*ScreenHeight {
+		t.Fatalf("expected %d pixels; got: %d", ScreenWidth*ScreenHeight, len(pixels))
+	}
+	p := g.Palette()
+	tt := []struct {
+		name     string
+		index    int
+		expected uint32
+	}{
+		{name: "Unlit", index: 0, expected: argb(p[0])},
+		{name: "Plane 1", index: 1, expected: argb(p[1])},
+		{name: "Both planes, second row", index: ScreenWidth, expected: argb(p[3])},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if pixels[tc.index] != tc.expected {
+				t.Errorf("expected: %08X; got: %08X", tc.expected, pixels[tc.index])
+			}
+		})
+	}
+
+	//The buffer grows to fit the hi-res screen.
+	g.SetHighRes(true)
+	pixels = g.argbPixels(pixels)
+	if len(pixels) != HiResWidth*HiResHeight {
+		t.Errorf("expected %d pixels; got: %d", HiResWidth*HiResHeight, len(pixels))
+	}
+}
+
+//A filtered image packs to the same pixels as the unfiltered screen.
+func TestImagePixels(t *testing.T) {
+	m := Memory{}
+	g := NewGraphics(&m)
+	g.screen[5][3] = 1
+	g.screen[6][3] = 2
+
+	expected := g.argbPixels(nil)
+	got := imagePixels(Render(g.Frame(), g.Palette()), nil)
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d pixels; got: %d", len(expected), len(got))
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("pixel %d - expected: %08X; got: %08X", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestCyclePalette(t *testing.T) {
+	m := Memory{}
+	g := NewGraphics(&m)
+	names := PaletteNames()
+
+	//Cycling goes through every palette and back to the start.
+	start := g.Palette()
+	for i := 0; i < len(names); i++ {
+		g.CyclePalette()
+	}
+	if g.Palette() != start {
+		t.Errorf("expected to be back on %s", DefaultPalette)
+	}
+
+	//Custom colours cycle from the first palette.
+	g.SetColours(color.White, color.Black)
+	if got := g.CyclePalette(); got != names[0] {
+		t.Errorf("expected: %s; got: %s", names[0], got)
+	}
+}
@@ -0,0 +1,32 @@
+package chip8
+
+import (
+	"fmt"
+)
+
+//NewHeadlessCPU returns a CPU with no window, keyboard or sound: a Framebuffer, a VirtualKeypad,
+//a RecordingBeeper and timers stepped by RunFrame, so runs are repeatable. Init still needs calling.
+func NewHeadlessCPU(q Quirks) *CPU {
+	m := &Memory{}
+	return NewCPU(m, NewFramebuffer(m), NewVirtualKeypad(), NewManualTimer(), NewManualTimer(), NewRecordingBeeper(), q)
+}
+
+//RunUntil runs whole frames until Cycles reaches cycles, then the instructions left over.
+//Stops early without an error if the program exits.
+func (c *CPU) RunUntil(cycles uint64) error {
+	for c.Cycles < cycles && !c.Halted {
+		var res StepResult
+		if left := cycles - c.Cycles; left < uint64(c.InstructionsPerFrame()) {
+			res = c.RunCycles(int(left))
+		} else {
+			res = c.RunFrame()
+		}
+		if c.Halted {
+			return nil
+		}
+		if res.Err != nil {
+			return fmt.Errorf("0x%03X: %v", res.PCBefore, res.Err)
+		}
+	}
+	return nil
+}
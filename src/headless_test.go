@@ -0,0 +1,51 @@
+package chip8
+
+import (
+	"testing"
+)
+
+func TestRunUntil(t *testing.T) {
+	tt := []struct {
+		name      string
+		program   []byte
+		cycles    uint64
+		expected  uint64
+		halted    bool
+		expectErr bool
+	}{
+		//ADD V0, 1; JP 0x200
+		{name: "Stops part way through a frame", program: []byte{0x70, 0x01, 0x12, 0x00}, cycles: 25, expected: 25},
+		{name: "Whole frames", program: []byte{0x70, 0x01, 0x12, 0x00}, cycles: 30, expected: 30},
+		//LD V0, 1; EXIT
+		{name: "Program exits", program: []byte{0x60, 0x01, 0x00, 0xFD}, cycles: 100, expected: 2, halted: true},
+		//LD V0, 1; then an invalid instruction
+		{name: "Bad instruction", program: []byte{0x60, 0x01, 0x00, 0x00}, cycles: 100, expected: 1, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := NewHeadlessCPU(Quirks{})
+			c8.IPS = 10 * FrameRate
+			c8.SetPlatform(PlatformSCHIP)
+			err := c8.Init()
+			if err != nil {
+				t.Fatalf("failed to init: %v", err)
+			}
+			err = c8.LoadProgram(tc.program)
+			if err != nil {
+				t.Fatalf("failed to load program: %v", err)
+			}
+
+			err = c8.RunUntil(tc.cycles)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %v; got: %v", tc.expectErr, err)
+			}
+			if c8.Cycles != tc.expected {
+				t.Errorf("cycles - expected: %d; got: %d", tc.expected, c8.Cycles)
+			}
+			if c8.Halted != tc.halted {
+				t.Errorf("halted - expected: %v; got: %v", tc.halted, c8.Halted)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+//go:build !nosdl
+// +build !nosdl
+
+package chip8
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+//Hotkeys handled by Run.
+const (
+	//HotkeySave writes a save state to StatePath.
+	HotkeySave = sdl.K_F5
+	//HotkeyLoad restores the save state from StatePath.
+	HotkeyLoad = sdl.K_F9
+	//HotkeyPalette switches to the next colour palette.
+	HotkeyPalette = sdl.K_F6
+	//HotkeyFullscreen switches between the window and fullscreen.
+	HotkeyFullscreen = sdl.K_F11
+	//HotkeyScreenshot writes the screen to a PNG starting with ScreenshotPath.
+	HotkeyScreenshot = sdl.K_F12
+	//HotkeyRecord starts and stops recording a GIF starting with ScreenshotPath.
+	HotkeyRecord = sdl.K_F8
+	//HotkeyRewind steps back a frame at a time while it's held, if Rewind is set.
+	HotkeyRewind = sdl.K_BACKSPACE
+)
+
+//paletteCycler is a Display with switchable palettes, like Graphics.
+type paletteCycler interface {
+	CyclePalette() string
+}
+
+//fullscreener is a Display that can go fullscreen, like Graphics.
+type fullscreener interface {
+	ToggleFullscreen() (bool, error)
+}
+
+//hotkey runs the action for an emulator hotkey, anything else is ignored.
+//Failures are printed rather than returned so they don't stop the game.
+func (c *CPU) hotkey(key sdl.Keycode) {
+	if c.movie != nil && (key == HotkeySave || key == HotkeyLoad) {
+		fmt.Printf("Hotkey failed: %v\n", errMovie)
+		return
+	}
+	var err error
+	switch key {
+	case HotkeySave:
+		err = c.SaveFile(c.StatePath)
+		if err == nil {
+			fmt.Printf("Saved state to %s\n", c.StatePath)
+		}
+	case HotkeyLoad:
+		err = c.LoadFile(c.StatePath)
+		if err == nil {
+			fmt.Printf("Loaded state from %s\n", c.StatePath)
+		}
+	case HotkeyPalette:
+		if p, ok := c.G.(paletteCycler); ok {
+			fmt.Printf("Palette: %s\n", p.CyclePalette())
+		}
+	case HotkeyScreenshot:
+		var name string
+		name, err = c.Screenshot()
+		if err == nil {
+			fmt.Printf("Saved screenshot to %s\n", name)
+		}
+	case HotkeyRecord:
+		err = c.ToggleRecording()
+	case HotkeyFullscreen:
+		if f, ok := c.G.(fullscreener); ok {
+			_, err = f.ToggleFullscreen()
+		}
+	}
+	if err != nil {
+		fmt.Printf("Hotkey failed: %v\n", err)
+	}
+}
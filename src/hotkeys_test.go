@@ -0,0 +1,43 @@
+//go:build !nosdl
+// +build !nosdl
+
+package chip8
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+//The save state hotkeys do nothing while a movie is recording.
+func TestHotkeysDuringMovie(t *testing.T) {
+	c8 := setup()
+	err := c8.LoadProgram(movieProgram)
+	if err != nil {
+		t.Fatalf("failed to load program: %v", err)
+	}
+	c8.StatePath = filepath.Join(t.TempDir(), "run.state")
+	err = c8.SaveFile(c8.StatePath)
+	if err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	m := c8.StartMovie(movieProgram, 42)
+	res := c8.RunFrame()
+	if res.Err != nil {
+		t.Fatalf("failed to run frame: %v", res.Err)
+	}
+	cycles := c8.Cycles
+	c8.hotkey(HotkeyLoad)
+	if c8.Cycles != cycles {
+		t.Errorf("expected loading a state during a recording to be refused; went from cycle %d to %d", cycles, c8.Cycles)
+	}
+
+	err = c8.EndMovie(m)
+	if err != nil {
+		t.Fatalf("failed to end movie: %v", err)
+	}
+	c8.hotkey(HotkeyLoad)
+	if c8.Cycles != 0 {
+		t.Errorf("expected the state to load once the movie ended; at cycle %d", c8.Cycles)
+	}
+}
@@ -1,69 +1,109 @@
+//go:build !nosdl
+// +build !nosdl
+
 package chip8
 
 import (
 	"fmt"
 
-	"github.com/Neffats/bimap"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
 //Input handles the state info of the keyboard.
 type Input struct {
+	//keys is SDL's keyboard state, indexed by scancode.
 	keys []uint8
 
-	keymap *bimap.Uint8
+	//names are the key names given to SetKeymap, Init turns them into scancodes.
+	names map[uint8][]string
+	//scancode looks up a key name, it needs SDL to be initialised.
+	scancode func(name string) sdl.Scancode
+
+	//keymap has the scancodes that press each CHIP-8 key, hostkeys is the reverse.
+	keymap   [16][]sdl.Scancode
+	hostkeys map[sdl.Scancode]uint8
 }
 
 //NewInput returns an empty uninitialised Input struct.
 //Should I init() here?
 func NewInput() *Input {
-	return &Input{}
+	return &Input{names: DefaultKeymap, scancode: scancodeFromName}
+}
+
+//scancodeFromName returns the scancode of the SDL key name, SCANCODE_UNKNOWN if there isn't one.
+func scancodeFromName(name string) sdl.Scancode {
+	return sdl.GetScancodeFromKey(sdl.GetKeyFromName(name))
 }
 
-//Init sets up the keys array.
+//Init sets up the keys array and the keymap. SDL must be initialised first, key names can't be looked up before.
+//An unknown key name is returned as a ConfigError for that key's keymap field.
 func (i *Input) Init() error {
 	//Only have to call once.
 	i.keys = sdl.GetKeyboardState()
 
-	//Set up the keyboard translation mappings.
-	i.keymap = bimap.NewUint8()
-
-	//TODO: Use a config file to set these mappings up.
-	i.keymap.Put(0x1, uint8(sdl.K_1))
-	i.keymap.Put(0x2, uint8(sdl.K_2))
-	i.keymap.Put(0x3, uint8(sdl.K_3))
-	i.keymap.Put(0xC, uint8(sdl.K_4))
-	i.keymap.Put(0x4, uint8(sdl.K_q))
-	i.keymap.Put(0x5, uint8(sdl.K_w))
-	i.keymap.Put(0x6, uint8(sdl.K_e))
-	i.keymap.Put(0xD, uint8(sdl.K_r))
-	i.keymap.Put(0x7, uint8(sdl.K_a))
-	i.keymap.Put(0x8, uint8(sdl.K_s))
-	i.keymap.Put(0x9, uint8(sdl.K_d))
-	i.keymap.Put(0xE, uint8(sdl.K_f))
-	i.keymap.Put(0xA, uint8(sdl.K_BACKSLASH))
-	i.keymap.Put(0x0, uint8(sdl.K_z))
-	i.keymap.Put(0xB, uint8(sdl.K_x))
-	i.keymap.Put(0xF, uint8(sdl.K_c))
+	var m [16][]sdl.Scancode
+	hostkeys := make(map[sdl.Scancode]uint8)
+	for key, names := range i.names {
+		for _, name := range names {
+			code := i.scancode(name)
+			if code == sdl.SCANCODE_UNKNOWN {
+				return &ConfigError{Field: fmt.Sprintf("keymap.%X", key), Msg: fmt.Sprintf("unknown key %q", name)}
+			}
+			m[key] = append(m[key], code)
+			hostkeys[code] = key
+		}
+	}
+	i.keymap = m
+	i.hostkeys = hostkeys
+	return nil
+}
 
+//SetKeymap maps each CHIP-8 key to the keyboard keys that press it, by SDL key name e.g. "Q" or "Up".
+//The names are looked up by Init.
+func (i *Input) SetKeymap(keymap map[uint8][]string) error {
+	for key := range keymap {
+		if key > 0xF {
+			return fmt.Errorf("key out of bounds: %x", key)
+		}
+	}
+	i.names = keymap
 	return nil
 }
 
-//IsPressed returns true if the specified key is currently pressed.
+//Update does nothing, the keyboard state is refreshed by SDL when events are polled.
+func (i *Input) Update(cycle uint64) error {
+	return nil
+}
+
+//IsPressed returns true if any keyboard key mapped to the specified key is currently pressed.
 func (i *Input) IsPressed(key uint8) (bool, error) {
 	if key > 0xF {
 		return false, fmt.Errorf("key out of bounds: %x", key)
 	}
-	k, exists := i.keymap.GetByKey(key)
-	if !exists {
-		return false, fmt.Errorf("key received does not exist in keymap: %d", key)
-	}
-	if i.keys[k] == 1 {
-		return true, nil
+	for _, code := range i.keymap[key] {
+		if int(code) < len(i.keys) && i.keys[code] == 1 {
+			return true, nil
+		}
 	}
 	return false, nil
 }
 
+//State returns the held keys as a bit mask, bit n set for key n.
+func (i *Input) State() uint16 {
+	var keys uint16
+	for k := uint8(0); k <= 0xF; k++ {
+		if pressed, _ := i.IsPressed(k); pressed {
+			keys |= 1 << k
+		}
+	}
+	return keys
+}
+
+//SetState does nothing, which keys are held is up to the keyboard.
+func (i *Input) SetState(keys uint16) error {
+	return nil
+}
+
 //WaitForKey will loop and do nothing until specified key is pressed.
 func (i *Input) WaitForKey() (uint8, error) {
 
@@ -71,12 +111,10 @@ func (i *Input) WaitForKey() (uint8, error) {
 		switch t := event.(type) {
 		case *sdl.KeyboardEvent:
 			if k := t.GetType(); k == sdl.KEYDOWN {
-				key, exists := i.keymap.GetByValue(uint8(t.Keysym.Sym))
+				key, exists := i.hostkeys[t.Keysym.Scancode]
 				if !exists {
 					continue
 				}
-				fmt.Printf("SDL Key Pressed: %d\n", uint8(t.Keysym.Sym))
-				fmt.Printf("Local mapping Key Pressed: %d\n", key)
 				return key, nil
 			}
 		}
@@ -0,0 +1,62 @@
+//go:build !nosdl
+// +build !nosdl
+
+package chip8
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+//The keymap is set from the config before SDL is up, the key names are looked up by Init.
+func TestInputKeymap(t *testing.T) {
+	codes := map[string]sdl.Scancode{"W": 26, "Up": 82, "Q": 20}
+	for _, names := range DefaultKeymap {
+		for _, name := range names {
+			if _, ok := codes[name]; !ok {
+				codes[name] = sdl.Scancode(100 + len(codes))
+			}
+		}
+	}
+	//Like SDL, names can't be looked up until it's started, which main does after SetKeymap.
+	started := false
+	lookup := func(name string) sdl.Scancode {
+		if !started {
+			return sdl.SCANCODE_UNKNOWN
+		}
+		return codes[name]
+	}
+
+	cfg := &Config{Keymap: map[string][]string{"5": {"W", "Up"}, "a": {"Q"}}}
+	in := NewInput()
+	in.scancode = lookup
+	err := in.SetKeymap(cfg.Keys())
+	if err != nil {
+		t.Fatalf("failed to set keymap before SDL started: %v", err)
+	}
+	started = true
+	err = in.Init()
+	if err != nil {
+		t.Fatalf("failed to init: %v", err)
+	}
+	if key, ok := in.hostkeys[82]; !ok || key != 0x5 {
+		t.Errorf("expected Up to press 5; got: %x, %v", key, ok)
+	}
+	if key, ok := in.hostkeys[20]; !ok || key != 0xA {
+		t.Errorf("expected Q to press A; got: %x, %v", key, ok)
+	}
+
+	cfg = &Config{Keymap: map[string][]string{"5": {"Nope"}}}
+	in = NewInput()
+	in.scancode = lookup
+	err = in.SetKeymap(cfg.Keys())
+	if err != nil {
+		t.Fatalf("failed to set keymap: %v", err)
+	}
+	err = in.Init()
+	if err == nil || !strings.Contains(err.Error(), `keymap.5: unknown key "Nope"`) {
+		t.Errorf("expected an unknown key error; got: %v", err)
+	}
+}
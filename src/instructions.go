@@ -2,8 +2,6 @@ package chip8
 
 import (
 	"fmt"
-	"math/rand"
-	"time"
 )
 
 //CheckInst returns true if the msb of instruction matches expected.
@@ -92,9 +90,7 @@ func (c *CPU) SkipEqualVal(inst uint16) error {
 	//Convert to uint8 for comparison with reg.
 	val := uint8(inst & 0x00FF)
 	if c.V[reg] == val {
-		// Only increment by one since we'll increment PC again at end of main loop?
-		// TODO: Need to check this.
-		c.PC += 2
+		return c.skip()
 	}
 	return nil
 }
@@ -109,9 +105,7 @@ func (c *CPU) SkipNotEqualVal(inst uint16) error {
 	//Convert to uint8 for comparison with reg.
 	val := uint8(inst & 0x00FF)
 	if c.V[reg] != val {
-		// Only increment by one since we'll increment PC again at end of main loop?
-		// TODO: Need to check this.
-		c.PC += 2
+		return c.skip()
 	}
 	return nil
 }
@@ -126,9 +120,7 @@ func (c *CPU) SkipEqualReg(inst uint16) error {
 	regY := (inst & 0x00F0) >> 4
 
 	if c.V[regX] == c.V[regY] {
-		// Only increment by one since we'll increment PC again at end of main loop?
-		// TODO: Need to check this.
-		c.PC += 2
+		return c.skip()
 	}
 	return nil
 }
@@ -188,6 +180,9 @@ func (c *CPU) Or(inst uint16) error {
 	regX := (inst & 0x0F00) >> 8
 	regY := (inst & 0x00F0) >> 4
 	c.V[regX] = c.V[regX] | c.V[regY]
+	if c.Quirks.VFReset {
+		c.V[0xF] = 0
+	}
 	return nil
 }
 
@@ -203,6 +198,9 @@ func (c *CPU) And(inst uint16) error {
 	regX := (inst & 0x0F00) >> 8
 	regY := (inst & 0x00F0) >> 4
 	c.V[regX] = c.V[regX] & c.V[regY]
+	if c.Quirks.VFReset {
+		c.V[0xF] = 0
+	}
 	return nil
 }
 
@@ -218,6 +216,9 @@ func (c *CPU) Xor(inst uint16) error {
 	regX := (inst & 0x0F00) >> 8
 	regY := (inst & 0x00F0) >> 4
 	c.V[regX] = c.V[regX] ^ c.V[regY]
+	if c.Quirks.VFReset {
+		c.V[0xF] = 0
+	}
 	return nil
 }
 
@@ -269,7 +270,7 @@ func (c *CPU) Sub(inst uint16) error {
 	return nil
 }
 
-//ShiftRight - Set Vx = Vx SHR 1.
+//ShiftRight - Set Vx = Vx SHR 1, or Vy SHR 1 with the ShiftVy quirk.
 //Instruction Format: 8xy6
 func (c *CPU) ShiftRight(inst uint16) error {
 	if check := CheckInst(inst, 0x8000); !check {
@@ -279,9 +280,15 @@ func (c *CPU) ShiftRight(inst uint16) error {
 		return fmt.Errorf("received invalid ShiftRight instruction: %x", inst)
 	}
 	regX := (inst & 0x0F00) >> 8
+	regY := (inst & 0x00F0) >> 4
+	if c.Quirks.ShiftVy {
+		c.V[regX] = c.V[regY]
+	}
 
-	c.V[0xF] = c.V[regX] & 1
+	//VF is set last in case x is F.
+	flag := c.V[regX] & 1
 	c.V[regX] >>= 1
+	c.V[0xF] = flag
 
 	return nil
 }
@@ -309,7 +316,7 @@ func (c *CPU) SubN(inst uint16) error {
 	return nil
 }
 
-//ShiftLeft - Set Vx = Vx SHL 1.
+//ShiftLeft - Set Vx = Vx SHL 1, or Vy SHL 1 with the ShiftVy quirk.
 //Instruction Format: 8xyE
 func (c *CPU) ShiftLeft(inst uint16) error {
 	if check := CheckInst(inst, 0x8000); !check {
@@ -319,9 +326,15 @@ func (c *CPU) ShiftLeft(inst uint16) error {
 		return fmt.Errorf("received invalid ShiftLeft instruction: %x", inst)
 	}
 	regX := (inst & 0x0F00) >> 8
+	regY := (inst & 0x00F0) >> 4
+	if c.Quirks.ShiftVy {
+		c.V[regX] = c.V[regY]
+	}
 
-	c.V[0xF] = c.V[regX] >> 7
+	//VF is set last in case x is F.
+	flag := c.V[regX] >> 7
 	c.V[regX] <<= 1
+	c.V[0xF] = flag
 
 	return nil
 }
@@ -337,9 +350,7 @@ func (c *CPU) SkipNotEqualReg(inst uint16) error {
 	regY := (inst & 0x00F0) >> 4
 
 	if c.V[regX] != c.V[regY] {
-		// Only increment by one since we'll increment PC again at end of main loop?
-		// TODO: Need to check this.
-		c.PC += 2
+		return c.skip()
 	}
 
 	return nil
@@ -358,7 +369,7 @@ func (c *CPU) LoadI(inst uint16) error {
 	return nil
 }
 
-//JumpWithReg - Jump/Set PC to location nnn + V0.
+//JumpWithReg - Jump/Set PC to location nnn + V0, or xnn + Vx with the JumpVx quirk.
 //Instruction Format: Bnnn
 func (c *CPU) JumpWithReg(inst uint16) error {
 	if check := CheckInst(inst, 0xB000); !check {
@@ -366,7 +377,11 @@ func (c *CPU) JumpWithReg(inst uint16) error {
 	}
 
 	addr := inst & 0x0FFF
-	c.PC = addr + uint16(c.V[0])
+	reg := uint16(0)
+	if c.Quirks.JumpVx {
+		reg = (inst & 0x0F00) >> 8
+	}
+	c.PC = addr + uint16(c.V[reg])
 
 	return nil
 }
@@ -378,12 +393,10 @@ func (c *CPU) RandomAnd(inst uint16) error {
 		return fmt.Errorf("received invalid RandomAnd instruction: %x", inst)
 	}
 
-	rand.Seed(time.Now().UTC().UnixNano())
-
 	v := inst & 0x00FF
 	regX := (inst & 0x0F00) >> 8
 
-	c.V[regX] = uint8(v) & uint8(rand.Int31())
+	c.V[regX] = uint8(v) & c.Rand.Byte()
 
 	return nil
 }
@@ -392,14 +405,33 @@ func (c *CPU) RandomAnd(inst uint16) error {
 //Instruction Format: Dxyn
 func (c *CPU) DrawSprite(inst uint16) error {
 	if check := CheckInst(inst, 0xD000); !check {
-		return fmt.Errorf("received invalid RandomAnd instruction: %x", inst)
+		return fmt.Errorf("received invalid DrawSprite instruction: %x", inst)
 	}
 
-	x := int32((inst & 0x0F00) >> 8)
-	y := int32((inst & 0x00F0) >> 4)
+	regX := (inst & 0x0F00) >> 8
+	regY := (inst & 0x00F0) >> 4
+	x := int32(c.V[regX])
+	y := int32(c.V[regY])
 	size := uint8(inst & 0x000F)
 
-	collision, err := c.G.Draw(x, y, size, c.I)
+	if c.Quirks.DisplayWait {
+		//Only one draw per frame, keep running this instruction until the next frame starts.
+		if c.Cycles < c.nextDraw {
+			c.PC -= 2
+			return nil
+		}
+		ipf := uint64(c.InstructionsPerFrame())
+		c.nextDraw = (c.Cycles/ipf + 1) * ipf
+	}
+
+	var collision bool
+	var err error
+	//SUPER-CHIP uses Dxy0 to draw a 16x16 sprite.
+	if size == 0 && c.Platform >= PlatformSCHIP {
+		collision, err = c.G.DrawLarge(x, y, c.I)
+	} else {
+		collision, err = c.G.Draw(x, y, size, c.I)
+	}
 	if err != nil {
 		return fmt.Errorf("could not draw sprite onto screen: %v", err)
 	}
@@ -430,7 +462,7 @@ func (c *CPU) SkipIfKey(inst uint16) error {
 		return fmt.Errorf("could not read key state: %v", err)
 	}
 	if k {
-		c.PC += 2
+		return c.skip()
 	}
 
 	return nil
@@ -453,7 +485,7 @@ func (c *CPU) SkipIfNotKey(inst uint16) error {
 		return fmt.Errorf("could not read key state: %v", err)
 	}
 	if !k {
-		c.PC += 2
+		return c.skip()
 	}
 
 	return nil
@@ -521,6 +553,31 @@ func (c *CPU) SetDT(inst uint16) error {
 	return nil
 }
 
+//SetST will set the sound timer to the value of register x, the beeper sounds while it is non-zero.
+//Instruction Format: Fx18
+func (c *CPU) SetST(inst uint16) error {
+	if check := CheckInst(inst, 0xF000); !check {
+		return fmt.Errorf("received invalid SetST instruction: %x", inst)
+	}
+	if check := inst & 0x00FF; check != 0x0018 {
+		return fmt.Errorf("received invalid SetST instruction: %x", inst)
+	}
+
+	reg := (inst & 0x0F00) >> 8
+
+	err := c.ST.Set(uint(c.V[reg]))
+	if err != nil {
+		return fmt.Errorf("could not set ST: %v", err)
+	}
+
+	err = c.UpdateSound()
+	if err != nil {
+		return fmt.Errorf("could not update sound: %v", err)
+	}
+
+	return nil
+}
+
 //AddIReg will add the I register with the x register and store the result in I register.
 //Instruction Format: Fx1E
 func (c *CPU) AddIReg(inst uint16) error {
@@ -552,7 +609,7 @@ func (c *CPU) SetISprite(inst uint16) error {
 	if c.V[reg] > 0xF {
 		return fmt.Errorf("value in V[%x] is bigger than 0xF: %d", reg, c.V[reg])
 	}
-	c.I = uint16(c.V[reg] * 5)
+	c.I = FontAddr + uint16(c.V[reg])*5
 
 	return nil
 }
@@ -573,7 +630,7 @@ func (c *CPU) SplitDecimal(inst uint16) error {
 
 	hundred := (dec / 100) % 10
 	ten := (dec / 10) % 10
-	one := dec & 10
+	one := dec % 10
 
 	c.Memory.Write(byte(hundred), c.I)
 	c.Memory.Write(byte(ten), c.I+1)
@@ -583,6 +640,8 @@ func (c *CPU) SplitDecimal(inst uint16) error {
 }
 
 //StoreRegs will save the values of registers 0 through x to memory starting at address I.
+//With the LoadStoreIncrementI quirk I is left pointing after the last register,
+//with LoadStoreIncrementIByX at the last register.
 //Instruction Format: Fx55
 func (c *CPU) StoreRegs(inst uint16) error {
 	if check := CheckInst(inst, 0xF000); !check {
@@ -593,19 +652,31 @@ func (c *CPU) StoreRegs(inst uint16) error {
 	}
 
 	reg := (inst & 0x0F00) >> 8
-	e := c.V[reg]
 
-	for i := uint8(0); i <= e; i++ {
-		err := c.Memory.Write(byte(c.V[i]), c.I+uint16(i))
+	for i := uint16(0); i <= reg; i++ {
+		err := c.Memory.Write(byte(c.V[i]), c.I+i)
 		if err != nil {
-			return fmt.Errorf("could not write reg[%x] to memory address %x: %v", reg, c.I+uint16(i), err)
+			return fmt.Errorf("could not write reg[%x] to memory address %x: %v", i, c.I+i, err)
 		}
 	}
+	c.incrementI(reg)
 
 	return nil
 }
 
+//incrementI moves I on after Fx55 or Fx65 has used registers 0 through reg, as the quirks say.
+func (c *CPU) incrementI(reg uint16) {
+	switch {
+	case c.Quirks.LoadStoreIncrementI:
+		c.I += reg + 1
+	case c.Quirks.LoadStoreIncrementIByX:
+		c.I += reg
+	}
+}
+
 //LoadRegs will load the values starting at memory address I into registers 0 through x.
+//With the LoadStoreIncrementI quirk I is left pointing after the last register,
+//with LoadStoreIncrementIByX at the last register.
 //Instruction Format: Fx65
 func (c *CPU) LoadRegs(inst uint16) error {
 	if check := CheckInst(inst, 0xF000); !check {
@@ -616,18 +687,318 @@ func (c *CPU) LoadRegs(inst uint16) error {
 	}
 
 	reg := (inst & 0x0F00) >> 8
-	e := c.V[reg]
-	fmt.Printf("reg: %d", e)
 
 	var err error
 
-	for i := uint16(0); i <= uint16(e); i++ {
+	for i := uint16(0); i <= reg; i++ {
 		addr := c.I + i
 		c.V[i], err = c.Memory.Read(addr)
 		if err != nil {
 			return fmt.Errorf("could not read memory address %x into register[%x]: %v", c.I+uint16(i), i, err)
 		}
 	}
+	c.incrementI(reg)
+
+	return nil
+}
+
+//ScrollDown scrolls the screen down n pixels.
+//Instruction Format: 00Cn
+func (c *CPU) ScrollDown(inst uint16) error {
+	if check := inst & 0xFFF0; check != 0x00C0 {
+		return fmt.Errorf("received invalid ScrollDown instruction: %x", inst)
+	}
+
+	err := c.G.Scroll(0, int32(inst&0x000F))
+	if err != nil {
+		return fmt.Errorf("could not scroll the screen: %v", err)
+	}
+
+	return nil
+}
+
+//ScrollRight scrolls the screen right 4 pixels.
+//Instruction Format: 00FB
+func (c *CPU) ScrollRight(inst uint16) error {
+	if inst != 0x00FB {
+		return fmt.Errorf("received invalid ScrollRight instruction: %x", inst)
+	}
+
+	err := c.G.Scroll(4, 0)
+	if err != nil {
+		return fmt.Errorf("could not scroll the screen: %v", err)
+	}
+
+	return nil
+}
+
+//ScrollLeft scrolls the screen left 4 pixels.
+//Instruction Format: 00FC
+func (c *CPU) ScrollLeft(inst uint16) error {
+	if inst != 0x00FC {
+		return fmt.Errorf("received invalid ScrollLeft instruction: %x", inst)
+	}
+
+	err := c.G.Scroll(-4, 0)
+	if err != nil {
+		return fmt.Errorf("could not scroll the screen: %v", err)
+	}
+
+	return nil
+}
+
+//Exit halts the CPU, the program has finished.
+//Instruction Format: 00FD
+func (c *CPU) Exit(inst uint16) error {
+	if inst != 0x00FD {
+		return fmt.Errorf("received invalid Exit instruction: %x", inst)
+	}
+
+	c.Halted = true
+
+	return nil
+}
+
+//LowRes switches the screen to the 64x32 mode.
+//Instruction Format: 00FE
+func (c *CPU) LowRes(inst uint16) error {
+	if inst != 0x00FE {
+		return fmt.Errorf("received invalid LowRes instruction: %x", inst)
+	}
+
+	err := c.G.SetHighRes(false)
+	if err != nil {
+		return fmt.Errorf("could not switch to low resolution: %v", err)
+	}
+
+	return nil
+}
+
+//HighRes switches the screen to the 128x64 mode.
+//Instruction Format: 00FF
+func (c *CPU) HighRes(inst uint16) error {
+	if inst != 0x00FF {
+		return fmt.Errorf("received invalid HighRes instruction: %x", inst)
+	}
+
+	err := c.G.SetHighRes(true)
+	if err != nil {
+		return fmt.Errorf("could not switch to high resolution: %v", err)
+	}
+
+	return nil
+}
+
+//SetILargeSprite sets I register to the address of the large 8x10 hex sprite of the value in register x.
+//Instruction Format: Fx30
+func (c *CPU) SetILargeSprite(inst uint16) error {
+	if check := CheckInst(inst, 0xF000); !check {
+		return fmt.Errorf("received invalid SetILargeSprite instruction: %x", inst)
+	}
+	if check := inst & 0x00FF; check != 0x0030 {
+		return fmt.Errorf("received invalid SetILargeSprite instruction: %x", inst)
+	}
+
+	reg := (inst & 0x0F00) >> 8
+	if c.V[reg] > 0xF {
+		return fmt.Errorf("value in V[%x] is bigger than 0xF: %d", reg, c.V[reg])
+	}
+	c.I = LargeFontAddr + uint16(c.V[reg])*10
+
+	return nil
+}
+
+//StoreRPL will save the values of registers 0 through x to the RPL user flags, x must be below 8.
+//Instruction Format: Fx75
+func (c *CPU) StoreRPL(inst uint16) error {
+	if check := CheckInst(inst, 0xF000); !check {
+		return fmt.Errorf("received invalid StoreRPL instruction: %x", inst)
+	}
+	if check := inst & 0x00FF; check != 0x0075 {
+		return fmt.Errorf("received invalid StoreRPL instruction: %x", inst)
+	}
+
+	reg := (inst & 0x0F00) >> 8
+	if reg > 7 {
+		return fmt.Errorf("only 8 RPL flags, can't store up to V[%x]", reg)
+	}
+	copy(c.RPL[:reg+1], c.V[:reg+1])
+
+	return nil
+}
+
+//LoadRPL will load the RPL user flags into registers 0 through x, x must be below 8.
+//Instruction Format: Fx85
+func (c *CPU) LoadRPL(inst uint16) error {
+	if check := CheckInst(inst, 0xF000); !check {
+		return fmt.Errorf("received invalid LoadRPL instruction: %x", inst)
+	}
+	if check := inst & 0x00FF; check != 0x0085 {
+		return fmt.Errorf("received invalid LoadRPL instruction: %x", inst)
+	}
+
+	reg := (inst & 0x0F00) >> 8
+	if reg > 7 {
+		return fmt.Errorf("only 8 RPL flags, can't load up to V[%x]", reg)
+	}
+	copy(c.V[:reg+1], c.RPL[:reg+1])
+
+	return nil
+}
+
+//ScrollUp scrolls the selected planes up n pixels.
+//Instruction Format: 00Dn
+func (c *CPU) ScrollUp(inst uint16) error {
+	if check := inst & 0xFFF0; check != 0x00D0 {
+		return fmt.Errorf("received invalid ScrollUp instruction: %x", inst)
+	}
+
+	err := c.G.Scroll(0, -int32(inst&0x000F))
+	if err != nil {
+		return fmt.Errorf("could not scroll the screen: %v", err)
+	}
+
+	return nil
+}
+
+//SaveRange will save the values of registers x through y to memory starting at address I, leaving I alone.
+//If x is bigger than y the registers are saved in reverse order.
+//Instruction Format: 5xy2
+func (c *CPU) SaveRange(inst uint16) error {
+	if check := CheckInst(inst, 0x5000); !check {
+		return fmt.Errorf("received invalid SaveRange instruction: %x", inst)
+	}
+	if check := inst & 0x000F; check != 2 {
+		return fmt.Errorf("received invalid SaveRange instruction: %x", inst)
+	}
+
+	for i, reg := range registerRange(inst) {
+		err := c.Memory.Write(c.V[reg], c.I+uint16(i))
+		if err != nil {
+			return fmt.Errorf("could not write reg[%x] to memory address %x: %v", reg, c.I+uint16(i), err)
+		}
+	}
+
+	return nil
+}
+
+//LoadRange will load the values starting at memory address I into registers x through y, leaving I alone.
+//If x is bigger than y the registers are loaded in reverse order.
+//Instruction Format: 5xy3
+func (c *CPU) LoadRange(inst uint16) error {
+	if check := CheckInst(inst, 0x5000); !check {
+		return fmt.Errorf("received invalid LoadRange instruction: %x", inst)
+	}
+	if check := inst & 0x000F; check != 3 {
+		return fmt.Errorf("received invalid LoadRange instruction: %x", inst)
+	}
+
+	var err error
+	for i, reg := range registerRange(inst) {
+		c.V[reg], err = c.Memory.Read(c.I + uint16(i))
+		if err != nil {
+			return fmt.Errorf("could not read memory address %x into register[%x]: %v", c.I+uint16(i), reg, err)
+		}
+	}
+
+	return nil
+}
+
+//registerRange returns the registers from x to y inclusive, counting down if x is bigger.
+func registerRange(inst uint16) []uint16 {
+	regX := (inst & 0x0F00) >> 8
+	regY := (inst & 0x00F0) >> 4
+
+	var regs []uint16
+	if regX <= regY {
+		for r := regX; r <= regY; r++ {
+			regs = append(regs, r)
+		}
+	} else {
+		for r := regX; r >= regY && r <= regX; r-- {
+			regs = append(regs, r)
+		}
+	}
+	return regs
+}
+
+//LoadILong sets the I register to the 16 bit address in the word after the instruction.
+//Instruction Format: F000 nnnn
+func (c *CPU) LoadILong(inst uint16) error {
+	if inst != 0xF000 {
+		return fmt.Errorf("received invalid LoadILong instruction: %x", inst)
+	}
+
+	//The PC is already pointing at the address.
+	addr, err := c.Fetch()
+	if err != nil {
+		return fmt.Errorf("could not fetch address: %v", err)
+	}
+	c.I = addr
+	c.PC += 2
+
+	return nil
+}
+
+//SelectPlanes picks the drawing planes with the bit mask n, bit 0 for plane 1 and bit 1 for plane 2.
+//Instruction Format: Fn01
+func (c *CPU) SelectPlanes(inst uint16) error {
+	if check := CheckInst(inst, 0xF000); !check {
+		return fmt.Errorf("received invalid SelectPlanes instruction: %x", inst)
+	}
+	if check := inst & 0x00FF; check != 0x0001 {
+		return fmt.Errorf("received invalid SelectPlanes instruction: %x", inst)
+	}
+
+	err := c.G.SetPlanes(uint8((inst & 0x0F00) >> 8))
+	if err != nil {
+		return fmt.Errorf("could not select planes: %v", err)
+	}
+
+	return nil
+}
+
+//LoadAudio copies the 16 byte audio pattern starting at address I into the audio buffer.
+//Instruction Format: F002
+func (c *CPU) LoadAudio(inst uint16) error {
+	if inst != 0xF002 {
+		return fmt.Errorf("received invalid LoadAudio instruction: %x", inst)
+	}
+
+	var err error
+	for i := range c.Pattern {
+		c.Pattern[i], err = c.Memory.Read(c.I + uint16(i))
+		if err != nil {
+			return fmt.Errorf("could not read audio pattern from memory address %x: %v", c.I+uint16(i), err)
+		}
+	}
+	c.PatternLoaded = true
+
+	err = c.Sound.SetPattern(c.Pattern, c.Pitch)
+	if err != nil {
+		return fmt.Errorf("could not set audio pattern: %v", err)
+	}
+
+	return nil
+}
+
+//SetPitch sets the audio pattern playback rate to the value of register x.
+//Instruction Format: Fx3A
+func (c *CPU) SetPitch(inst uint16) error {
+	if check := CheckInst(inst, 0xF000); !check {
+		return fmt.Errorf("received invalid SetPitch instruction: %x", inst)
+	}
+	if check := inst & 0x00FF; check != 0x003A {
+		return fmt.Errorf("received invalid SetPitch instruction: %x", inst)
+	}
+
+	reg := (inst & 0x0F00) >> 8
+	c.Pitch = c.V[reg]
+
+	err := c.Sound.SetPattern(c.Pattern, c.Pitch)
+	if err != nil {
+		return fmt.Errorf("could not set audio pattern: %v", err)
+	}
 
 	return nil
 }
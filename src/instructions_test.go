@@ -1,6 +1,10 @@
 package chip8
 
-import "testing"
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
 
 func TestJump(t *testing.T) {
 	tt := []struct {
@@ -602,6 +606,12 @@ func TestShiftRight(t *testing.T) {
 				{reg: 0x0F, value: 1}},
 			expectErr: false},
 		{name: "Invalid Set Instruction", inst: 0x2AEB, expectErr: true},
+		{name: "Shift VF keeps the flag", inst: 0x8FB6,
+			reg: []treg{
+				treg{reg: 0x0F, value: 209}},
+			expected: []treg{
+				{reg: 0x0F, value: 1}},
+			expectErr: false},
 		{name: "Invalid ShiftRight Instruction", inst: 0x8AEB, expectErr: true},
 	}
 
@@ -716,6 +726,12 @@ func TestShiftLeft(t *testing.T) {
 				{reg: 0x0F, value: 1}},
 			expectErr: false},
 		{name: "Invalid Set Instruction", inst: 0x2AEB, expectErr: true},
+		{name: "Shift VF keeps the flag", inst: 0x8FBE,
+			reg: []treg{
+				treg{reg: 0x0F, value: 209}},
+			expected: []treg{
+				{reg: 0x0F, value: 1}},
+			expectErr: false},
 		{name: "Invalid ShiftLeft Instruction", inst: 0x8AEB, expectErr: true},
 	}
 
@@ -862,27 +878,691 @@ func TestRandomAnd(t *testing.T) {
 	tt := []struct {
 		name      string
 		inst      uint16
+		random    uint8
 		expected  treg
 		expectErr bool
 	}{
-		{name: "v5 - 155", inst: 0xC59B, expected: treg{reg: 0x05, value: 155}, expectErr: false},
-		{name: "vA - 255", inst: 0xCAFF, expected: treg{reg: 0x0A, value: 255}, expectErr: false},
+		{name: "v5 - 155", inst: 0xC59B, random: 0xF0, expected: treg{reg: 0x05, value: 0x90}, expectErr: false},
+		{name: "vA - 255", inst: 0xCAFF, random: 0x3C, expected: treg{reg: 0x0A, value: 0x3C}, expectErr: false},
+		{name: "v0 - 0", inst: 0xC000, random: 0xFF, expected: treg{reg: 0x00, value: 0x00}, expectErr: false},
 		{name: "Invalid Instruction", inst: 0x2AEB, expectErr: true},
 	}
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			c8 := setup()
+			c8.Rand = NewScriptedRandom(tc.random)
 			err := c8.RandomAnd(tc.inst)
 			if err != nil {
 				if tc.expectErr == true {
 					return
 				}
-				t.Fatalf("failed execute Or: %v", err)
+				t.Fatalf("failed execute RandomAnd: %v", err)
+			}
+			if c8.V[tc.expected.reg] != tc.expected.value {
+				t.Errorf("expected: %x; got: %x", tc.expected.value, c8.V[tc.expected.reg])
+			}
+		})
+	}
+}
+
+//The same seed gives the same numbers.
+func TestSeed(t *testing.T) {
+	a, b := setup(), setup()
+	a.Seed(7)
+	b.Seed(7)
+	same := true
+	for i := 0; i < 16; i++ {
+		a.RandomAnd(0xC0FF)
+		b.RandomAnd(0xC0FF)
+		if a.V[0] != b.V[0] {
+			same = false
+		}
+	}
+	if !same {
+		t.Errorf("expected the same numbers from the same seed")
+	}
+}
+
+func TestClearScreen(t *testing.T) {
+	tt := []struct {
+		name      string
+		inst      uint16
+		expectErr bool
+	}{
+		{name: "Clear screen", inst: 0x00E0, expectErr: false},
+		{name: "Invalid Instruction", inst: 0x10E0, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			c8.Init()
+			c8.I = 0
+			//Draw a filled row so there's something to clear.
+			c8.DrawSprite(0xD005)
+			err := c8.ClearScreen(tc.inst)
+			if err != nil {
+				if tc.expectErr == true {
+					return
+				}
+				t.Fatalf("failed execute ClearScreen: %v", err)
+			}
+			for x := int32(0); x < ScreenWidth; x++ {
+				for y := int32(0); y < ScreenHeight; y++ {
+					if p, _ := c8.G.Pixel(x, y); p != 0 {
+						t.Fatalf("expected pixel (%d, %d) to be cleared", x, y)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDrawSprite(t *testing.T) {
+	tt := []struct {
+		name      string
+		inst      uint16
+		reg       []treg
+		digit     uint16
+		draws     int
+		lit       [][2]int32
+		unlit     [][2]int32
+		vf        uint8
+		expectErr bool
+	}{
+		{name: "Draw 0 at (2, 3)", inst: 0xD015,
+			reg:   []treg{treg{reg: 0x0, value: 2}, treg{reg: 0x1, value: 3}},
+			digit: 0x0, draws: 1,
+			lit:   [][2]int32{{2, 3}, {5, 3}, {2, 4}, {5, 7}},
+			unlit: [][2]int32{{3, 4}, {6, 3}, {2, 8}},
+			vf:    0, expectErr: false},
+		{name: "Draw 1 twice collides", inst: 0xD235,
+			reg:   []treg{treg{reg: 0x2, value: 10}, treg{reg: 0x3, value: 10}},
+			digit: 0x1, draws: 2,
+			unlit: [][2]int32{{12, 10}, {11, 11}, {12, 14}},
+			vf:    1, expectErr: false},
+		{name: "Draw wraps around edges", inst: 0xDAB5,
+			reg:   []treg{treg{reg: 0xA, value: 62}, treg{reg: 0xB, value: 30}},
+			digit: 0x8, draws: 1,
+			lit:   [][2]int32{{62, 30}, {1, 30}, {62, 0}, {1, 2}},
+			unlit: [][2]int32{{2, 30}, {62, 3}},
+			vf:    0, expectErr: false},
+		{name: "Invalid Instruction", inst: 0x2AEB, draws: 1, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			c8.Init()
+			c8.I = tc.digit * 5
+			for _, r := range tc.reg {
+				c8.V[r.reg] = r.value
+			}
+			for i := 0; i < tc.draws; i++ {
+				err := c8.DrawSprite(tc.inst)
+				if err != nil {
+					if tc.expectErr == true {
+						return
+					}
+					t.Fatalf("failed execute DrawSprite: %v", err)
+				}
+			}
+			for _, p := range tc.lit {
+				if v, _ := c8.G.Pixel(p[0], p[1]); v != 1 {
+					t.Errorf("expected pixel (%d, %d) to be lit", p[0], p[1])
+				}
+			}
+			for _, p := range tc.unlit {
+				if v, _ := c8.G.Pixel(p[0], p[1]); v != 0 {
+					t.Errorf("expected pixel (%d, %d) to be unlit", p[0], p[1])
+				}
+			}
+			if c8.V[0xF] != tc.vf {
+				t.Errorf("VF - expected: %d; got: %d", tc.vf, c8.V[0xF])
+			}
+		})
+	}
+}
+
+func TestSkipIfKey(t *testing.T) {
+	tt := []struct {
+		name      string
+		inst      uint16
+		reg       treg
+		pressed   []uint8
+		expected  uint16
+		expectErr bool
+	}{
+		{name: "Key pressed", inst: 0xE39E, reg: treg{reg: 0x3, value: 0xA}, pressed: []uint8{0xA}, expected: PCInit + 2, expectErr: false},
+		{name: "Other key pressed", inst: 0xE39E, reg: treg{reg: 0x3, value: 0xA}, pressed: []uint8{0xB}, expected: PCInit, expectErr: false},
+		{name: "No key pressed", inst: 0xE39E, reg: treg{reg: 0x3, value: 0xA}, expected: PCInit, expectErr: false},
+		{name: "Key out of bounds", inst: 0xE39E, reg: treg{reg: 0x3, value: 0x10}, expected: PCInit, expectErr: true},
+		{name: "Invalid Instruction", inst: 0xE3A1, reg: treg{reg: 0x3, value: 0xA}, expected: PCInit, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			kp := NewVirtualKeypad()
+			c8.Input = kp
+			for _, k := range tc.pressed {
+				kp.Press(k)
+			}
+			c8.V[tc.reg.reg] = tc.reg.value
+			err := c8.SkipIfKey(tc.inst)
+			if err != nil {
+				if tc.expectErr == true {
+					return
+				}
+				t.Fatalf("failed execute SkipIfKey: %v", err)
+			}
+			if c8.PC != tc.expected {
+				t.Errorf("expected: %x; got: %x", tc.expected, c8.PC)
+			}
+		})
+	}
+}
+
+func TestSkipIfNotKey(t *testing.T) {
+	tt := []struct {
+		name      string
+		inst      uint16
+		reg       treg
+		pressed   []uint8
+		expected  uint16
+		expectErr bool
+	}{
+		{name: "Key pressed", inst: 0xE5A1, reg: treg{reg: 0x5, value: 0x1}, pressed: []uint8{0x1}, expected: PCInit, expectErr: false},
+		{name: "Other key pressed", inst: 0xE5A1, reg: treg{reg: 0x5, value: 0x1}, pressed: []uint8{0xF}, expected: PCInit + 2, expectErr: false},
+		{name: "No key pressed", inst: 0xE5A1, reg: treg{reg: 0x5, value: 0x1}, expected: PCInit + 2, expectErr: false},
+		{name: "Invalid Instruction", inst: 0xE59E, reg: treg{reg: 0x5, value: 0x1}, expected: PCInit, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			kp := NewVirtualKeypad()
+			c8.Input = kp
+			for _, k := range tc.pressed {
+				kp.Press(k)
+			}
+			c8.V[tc.reg.reg] = tc.reg.value
+			err := c8.SkipIfNotKey(tc.inst)
+			if err != nil {
+				if tc.expectErr == true {
+					return
+				}
+				t.Fatalf("failed execute SkipIfNotKey: %v", err)
+			}
+			if c8.PC != tc.expected {
+				t.Errorf("expected: %x; got: %x", tc.expected, c8.PC)
+			}
+		})
+	}
+}
+
+func TestWaitForKey(t *testing.T) {
+	tt := []struct {
+		name      string
+		inst      uint16
+		pressed   []uint8
+		scheduled []KeyEvent
+		expected  treg
+		expectErr bool
+	}{
+		{name: "Key already pressed", inst: 0xF20A, pressed: []uint8{0xC, 0x7}, expected: treg{reg: 0x2, value: 0x7}, expectErr: false},
+		{name: "Scheduled key press", inst: 0xF40A,
+			scheduled: []KeyEvent{
+				KeyEvent{Cycle: 100, Key: 0x3, Pressed: true},
+				KeyEvent{Cycle: 50, Key: 0x9, Pressed: false},
+			},
+			expected: treg{reg: 0x4, value: 0x3}, expectErr: false},
+		{name: "Nothing to wait for", inst: 0xF40A, expectErr: true},
+		{name: "Invalid Instruction", inst: 0xF40B, pressed: []uint8{0x1}, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			kp := NewVirtualKeypad()
+			c8.Input = kp
+			for _, k := range tc.pressed {
+				kp.Press(k)
+			}
+			for _, e := range tc.scheduled {
+				kp.Schedule(e.Cycle, e.Key, e.Pressed)
+			}
+			err := c8.WaitForKey(tc.inst)
+			if err != nil {
+				if tc.expectErr == true {
+					return
+				}
+				t.Fatalf("failed execute WaitForKey: %v", err)
+			}
+			if c8.V[tc.expected.reg] != tc.expected.value {
+				t.Errorf("expected: %x; got: %x", tc.expected.value, c8.V[tc.expected.reg])
+			}
+		})
+	}
+}
+
+func TestSetST(t *testing.T) {
+	tt := []struct {
+		name      string
+		inst      uint16
+		reg       treg
+		expected  uint
+		beeps     []BeepEvent
+		expectErr bool
+	}{
+		{name: "Set ST to 30", inst: 0xF618, reg: treg{reg: 0x6, value: 30}, expected: 30, beeps: []BeepEvent{{Cycle: 0, On: true}}, expectErr: false},
+		{name: "Set ST to 0", inst: 0xF618, reg: treg{reg: 0x6, value: 0}, expected: 0, expectErr: false},
+		{name: "Invalid Instruction", inst: 0xF615, reg: treg{reg: 0x6, value: 30}, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			b := NewRecordingBeeper()
+			c8.Sound = b
+			c8.V[tc.reg.reg] = tc.reg.value
+			err := c8.SetST(tc.inst)
+			if err != nil {
+				if tc.expectErr == true {
+					return
+				}
+				t.Fatalf("failed execute SetST: %v", err)
+			}
+			got, _ := c8.ST.Get()
+			if got != tc.expected {
+				t.Errorf("ST - expected: %d; got: %d", tc.expected, got)
 			}
-			if c8.V[tc.expected.reg] == tc.expected.value {
-				t.Errorf("expected not to get: %x; got: %x", tc.expected.value, c8.V[tc.expected.reg])
+			if len(b.Events) != len(tc.beeps) {
+				t.Fatalf("beeps - expected: %v; got: %v", tc.beeps, b.Events)
+			}
+			for i := range tc.beeps {
+				if b.Events[i] != tc.beeps[i] {
+					t.Errorf("beeps - expected: %v; got: %v", tc.beeps, b.Events)
+				}
+			}
+		})
+	}
+}
+
+func TestScroll(t *testing.T) {
+	tt := []struct {
+		name      string
+		inst      uint16
+		hires     bool
+		lit       [][2]int32
+		unlit     [][2]int32
+		expectErr bool
+	}{
+		{name: "Scroll down 3", inst: 0x00C3,
+			lit:   [][2]int32{{10, 13}},
+			unlit: [][2]int32{{10, 10}}, expectErr: false},
+		{name: "Scroll right", inst: 0x00FB,
+			lit:   [][2]int32{{14, 10}},
+			unlit: [][2]int32{{10, 10}}, expectErr: false},
+		{name: "Scroll left", inst: 0x00FC,
+			lit:   [][2]int32{{6, 10}},
+			unlit: [][2]int32{{10, 10}}, expectErr: false},
+		{name: "Scroll in hi-res", inst: 0x00CF, hires: true,
+			lit:   [][2]int32{{10, 25}},
+			unlit: [][2]int32{{10, 10}}, expectErr: false},
+		{name: "Invalid Instruction", inst: 0x00E3, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			c8.G.SetHighRes(tc.hires)
+			frame := c8.G.Frame()
+			frame.Pix[10*frame.W+10] = 1
+			c8.G.SetFrame(frame)
+
+			var err error
+			switch tc.inst {
+			case 0x00FB:
+				err = c8.ScrollRight(tc.inst)
+			case 0x00FC:
+				err = c8.ScrollLeft(tc.inst)
+			default:
+				err = c8.ScrollDown(tc.inst)
+			}
+			if err != nil {
+				if tc.expectErr == true {
+					return
+				}
+				t.Fatalf("failed execute scroll: %v", err)
+			}
+			for _, p := range tc.lit {
+				if v, _ := c8.G.Pixel(p[0], p[1]); v != 1 {
+					t.Errorf("expected pixel (%d, %d) to be lit", p[0], p[1])
+				}
+			}
+			for _, p := range tc.unlit {
+				if v, _ := c8.G.Pixel(p[0], p[1]); v != 0 {
+					t.Errorf("expected pixel (%d, %d) to be unlit", p[0], p[1])
+				}
+			}
+		})
+	}
+}
+
+func TestHighRes(t *testing.T) {
+	c8 := setup()
+	c8.Init()
+
+	err := c8.HighRes(0x00FF)
+	if err != nil {
+		t.Fatalf("failed execute HighRes: %v", err)
+	}
+	if !c8.G.HighRes() {
+		t.Fatalf("expected hi-res mode")
+	}
+	if f := c8.G.Frame(); f.W != HiResWidth || f.H != HiResHeight {
+		t.Errorf("frame - expected: %dx%d; got: %dx%d", HiResWidth, HiResHeight, f.W, f.H)
+	}
+
+	//A 16x16 sprite at the bottom right corner, in range only in hi-res.
+	c8.Platform = PlatformSCHIP
+	c8.I = LargeFontAddr
+	c8.V[0] = 112
+	c8.V[1] = 48
+	err = c8.DrawSprite(0xD010)
+	if err != nil {
+		t.Fatalf("failed execute DrawSprite: %v", err)
+	}
+	if v, _ := c8.G.Pixel(112, 48); v != 1 {
+		t.Errorf("expected pixel (112, 48) to be lit")
+	}
+
+	err = c8.LowRes(0x00FE)
+	if err != nil {
+		t.Fatalf("failed execute LowRes: %v", err)
+	}
+	if c8.G.HighRes() {
+		t.Errorf("expected lo-res mode")
+	}
+	if _, err := c8.G.Pixel(112, 48); err == nil {
+		t.Errorf("expected pixel (112, 48) to be out of bounds")
+	}
+}
+
+func TestSetILargeSprite(t *testing.T) {
+	tt := []struct {
+		name      string
+		inst      uint16
+		reg       treg
+		expected  uint16
+		expectErr bool
+	}{
+		{name: "Digit 0", inst: 0xF030, reg: treg{reg: 0x0, value: 0}, expected: LargeFontAddr, expectErr: false},
+		{name: "Digit A", inst: 0xF530, reg: treg{reg: 0x5, value: 0xA}, expected: LargeFontAddr + 100, expectErr: false},
+		{name: "Not a digit", inst: 0xF530, reg: treg{reg: 0x5, value: 0x10}, expectErr: true},
+		{name: "Invalid Instruction", inst: 0xF529, reg: treg{reg: 0x5, value: 0x1}, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			c8.V[tc.reg.reg] = tc.reg.value
+			err := c8.SetILargeSprite(tc.inst)
+			if err != nil {
+				if tc.expectErr == true {
+					return
+				}
+				t.Fatalf("failed execute SetILargeSprite: %v", err)
+			}
+			if tc.expectErr {
+				t.Fatalf("expected an error")
+			}
+			if c8.I != tc.expected {
+				t.Errorf("I - expected: %x; got: %x", tc.expected, c8.I)
+			}
+		})
+	}
+}
+
+func TestRPL(t *testing.T) {
+	c8 := setup()
+	for i := range c8.V {
+		c8.V[i] = uint8(i + 1)
+	}
+
+	err := c8.StoreRPL(0xF375)
+	if err != nil {
+		t.Fatalf("failed execute StoreRPL: %v", err)
+	}
+	err = c8.StoreRPL(0xF875)
+	if err == nil {
+		t.Errorf("expected an error storing more than 8 flags")
+	}
+
+	c8.V = [16]uint8{}
+	err = c8.LoadRPL(0xF285)
+	if err != nil {
+		t.Fatalf("failed execute LoadRPL: %v", err)
+	}
+	expected := [16]uint8{1, 2, 3}
+	if c8.V != expected {
+		t.Errorf("V - expected: %v; got: %v", expected, c8.V)
+	}
+}
+
+func TestRange(t *testing.T) {
+	tt := []struct {
+		name      string
+		inst      uint16
+		expected  []byte
+		expectErr bool
+	}{
+		{name: "Save V1 to V3", inst: 0x5132, expected: []byte{1, 2, 3}, expectErr: false},
+		{name: "Save V3 to V1 reversed", inst: 0x5312, expected: []byte{3, 2, 1}, expectErr: false},
+		{name: "Save a single register", inst: 0x5552, expected: []byte{5, 0}, expectErr: false},
+		{name: "Invalid Instruction", inst: 0x5130, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			for i := range c8.V {
+				c8.V[i] = uint8(i)
+			}
+			c8.I = 0x300
+			err := c8.SaveRange(tc.inst)
+			if err != nil {
+				if tc.expectErr == true {
+					return
+				}
+				t.Fatalf("failed execute SaveRange: %v", err)
+			}
+			for i, b := range tc.expected {
+				got, _ := c8.Memory.Read(0x300 + uint16(i))
+				if got != b {
+					t.Errorf("memory[%x] - expected: %d; got: %d", 0x300+i, b, got)
+				}
+			}
+			if c8.I != 0x300 {
+				t.Errorf("I - expected: %x; got: %x", 0x300, c8.I)
+			}
+
+			//Loading it back should give the same registers.
+			saved := c8.V
+			c8.V = [16]uint8{}
+			err = c8.LoadRange(tc.inst + 1)
+			if err != nil {
+				t.Fatalf("failed execute LoadRange: %v", err)
+			}
+			for _, r := range registerRange(tc.inst) {
+				if c8.V[r] != saved[r] {
+					t.Errorf("V[%x] - expected: %d; got: %d", r, saved[r], c8.V[r])
+				}
 			}
 		})
 	}
 }
+
+func TestPlanes(t *testing.T) {
+	c8 := setup()
+	c8.Init()
+	c8.I = FontAddr
+
+	//Both planes take the next 5 bytes of sprite data, so plane 2 gets the "1".
+	err := c8.SelectPlanes(0xF301)
+	if err != nil {
+		t.Fatalf("failed execute SelectPlanes: %v", err)
+	}
+	err = c8.DrawSprite(0xD005)
+	if err != nil {
+		t.Fatalf("failed execute DrawSprite: %v", err)
+	}
+	expected := map[[2]int32]uint8{{0, 0}: 1, {2, 0}: 3, {1, 1}: 2, {2, 1}: 2, {0, 1}: 1, {3, 1}: 1, {4, 1}: 0}
+	for p, v := range expected {
+		if got, _ := c8.G.Pixel(p[0], p[1]); got != v {
+			t.Errorf("pixel (%d, %d) - expected: %d; got: %d", p[0], p[1], v, got)
+		}
+	}
+
+	//Clearing plane 1 leaves plane 2 alone.
+	c8.SelectPlanes(0xF101)
+	c8.ClearScreen(0x00E0)
+	if got, _ := c8.G.Pixel(2, 0); got != 2 {
+		t.Errorf("pixel (2, 0) - expected: 2; got: %d", got)
+	}
+
+	err = c8.SelectPlanes(0xF401)
+	if err == nil {
+		t.Errorf("expected an error selecting plane mask 4")
+	}
+}
+
+func TestAudioPattern(t *testing.T) {
+	c8 := setup()
+	b := NewRecordingBeeper()
+	c8.Sound = b
+	c8.SetPlatform(PlatformXOCHIP)
+
+	pattern := [16]byte{0xFF, 0x00, 0xF0, 0x0F}
+	for i, v := range pattern {
+		c8.Memory.Write(v, 0x1200+uint16(i))
+	}
+	c8.I = 0x1200
+	err := c8.LoadAudio(0xF002)
+	if err != nil {
+		t.Fatalf("failed execute LoadAudio: %v", err)
+	}
+	c8.V[4] = 100
+	err = c8.SetPitch(0xF43A)
+	if err != nil {
+		t.Fatalf("failed execute SetPitch: %v", err)
+	}
+
+	if b.Pattern != pattern {
+		t.Errorf("pattern - expected: % X; got: % X", pattern, b.Pattern)
+	}
+	if b.Pitch != 100 {
+		t.Errorf("pitch - expected: 100; got: %d", b.Pitch)
+	}
+}
+
+func TestSplitDecimal(t *testing.T) {
+	tt := []struct {
+		name      string
+		inst      uint16
+		reg       treg
+		expected  [3]byte
+		expectErr bool
+	}{
+		{name: "Split 137", inst: 0xF333, reg: treg{reg: 0x3, value: 137}, expected: [3]byte{1, 3, 7}, expectErr: false},
+		{name: "Split 9", inst: 0xF333, reg: treg{reg: 0x3, value: 9}, expected: [3]byte{0, 0, 9}, expectErr: false},
+		{name: "Split 255", inst: 0xF333, reg: treg{reg: 0x3, value: 255}, expected: [3]byte{2, 5, 5}, expectErr: false},
+		//The ones digit used to be worked out with & 10 rather than % 10.
+		{name: "Split 10", inst: 0xF333, reg: treg{reg: 0x3, value: 10}, expected: [3]byte{0, 1, 0}, expectErr: false},
+		{name: "Invalid Instruction", inst: 0xF329, expectErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			c8.I = 0x300
+			c8.V[tc.reg.reg] = tc.reg.value
+			err := c8.SplitDecimal(tc.inst)
+			if err != nil {
+				if tc.expectErr == true {
+					return
+				}
+				t.Fatalf("failed execute SplitDecimal: %v", err)
+			}
+			for i, b := range tc.expected {
+				got, _ := c8.Memory.Read(0x300 + uint16(i))
+				if got != b {
+					t.Errorf("memory[%x] - expected: %d; got: %d", 0x300+i, b, got)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreLoadRegs(t *testing.T) {
+	c8 := setup()
+	for i := range c8.V {
+		c8.V[i] = uint8(0x10 + i)
+	}
+	//V3 holds a value bigger than 3, only V0 to V3 should be stored.
+	c8.I = 0x300
+	err := c8.StoreRegs(0xF355)
+	if err != nil {
+		t.Fatalf("failed execute StoreRegs: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		expected := byte(0x10 + i)
+		if i == 4 {
+			expected = 0
+		}
+		got, _ := c8.Memory.Read(0x300 + uint16(i))
+		if got != expected {
+			t.Errorf("memory[%x] - expected: %x; got: %x", 0x300+i, expected, got)
+		}
+	}
+
+	//V2 is 0 here, only V0 would be loaded if the count came from V2 rather than x.
+	c8.V = [16]uint8{}
+	err = c8.LoadRegs(0xF265)
+	if err != nil {
+		t.Fatalf("failed execute LoadRegs: %v", err)
+	}
+	expected := [16]uint8{0x10, 0x11, 0x12}
+	if c8.V != expected {
+		t.Errorf("V - expected: %v; got: %v", expected, c8.V)
+	}
+}
+
+//LoadRegs used to print the register count to stdout.
+func TestLoadRegsQuiet(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("could not create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	c8 := setup()
+	c8.I = 0x300
+	err = c8.LoadRegs(0xF365)
+	os.Stdout = stdout
+	w.Close()
+	if err != nil {
+		t.Fatalf("failed execute LoadRegs: %v", err)
+	}
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("could not read stdout: %v", err)
+	}
+	if len(out) != 0 {
+		t.Errorf("expected nothing on stdout; got: %q", out)
+	}
+}
@@ -0,0 +1,220 @@
+package chip8
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//KeyEvent is a key press or release scheduled for a given cycle.
+type KeyEvent struct {
+	Cycle   uint64
+	Key     uint8
+	Pressed bool
+	//Wait marks the key Fx0A got on that cycle, rather than a press or release.
+	Wait bool `json:",omitempty"`
+}
+
+//VirtualKeypad is a keypad driven by code instead of a keyboard.
+//Keys can be pressed and released directly, or scheduled to change on a cycle number.
+type VirtualKeypad struct {
+	keys [16]bool
+
+	//Scheduled events, kept sorted by cycle.
+	events []KeyEvent
+	cycle  uint64
+	//waits are the keys WaitForKey returns, in order, once their cycle is reached.
+	waits []KeyEvent
+
+	mux sync.RWMutex
+}
+
+//NewVirtualKeypad returns a keypad with no keys pressed.
+func NewVirtualKeypad() *VirtualKeypad {
+	return &VirtualKeypad{}
+}
+
+//Press holds down the specified key.
+func (v *VirtualKeypad) Press(key uint8) error {
+	return v.set(key, true)
+}
+
+//Release lets go of the specified key.
+func (v *VirtualKeypad) Release(key uint8) error {
+	return v.set(key, false)
+}
+
+func (v *VirtualKeypad) set(key uint8, pressed bool) error {
+	if key > 0xF {
+		return fmt.Errorf("key out of bounds: %x", key)
+	}
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	v.keys[key] = pressed
+	return nil
+}
+
+//Schedule a key to be pressed or released once the CPU reaches the specified cycle.
+//Events scheduled for the same cycle are applied in the order they were added.
+func (v *VirtualKeypad) Schedule(cycle uint64, key uint8, pressed bool) error {
+	if key > 0xF {
+		return fmt.Errorf("key out of bounds: %x", key)
+	}
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
+	i := sort.Search(len(v.events), func(i int) bool { return v.events[i].Cycle > cycle })
+	v.events = append(v.events, KeyEvent{})
+	copy(v.events[i+1:], v.events[i:])
+	v.events[i] = KeyEvent{Cycle: cycle, Key: key, Pressed: pressed}
+
+	return nil
+}
+
+//ScheduleWait makes WaitForKey return the key once the CPU reaches the cycle, whatever else is held.
+//Used to replay movies, which record the key Fx0A got rather than working it out again.
+func (v *VirtualKeypad) ScheduleWait(cycle uint64, key uint8) error {
+	if key > 0xF {
+		return fmt.Errorf("key out of bounds: %x", key)
+	}
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	v.waits = append(v.waits, KeyEvent{Cycle: cycle, Key: key, Wait: true})
+	return nil
+}
+
+//Update applies every scheduled event up to and including the specified cycle.
+func (v *VirtualKeypad) Update(cycle uint64) error {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
+	if cycle > v.cycle {
+		v.cycle = cycle
+	}
+	for len(v.events) > 0 && v.events[0].Cycle <= v.cycle {
+		v.keys[v.events[0].Key] = v.events[0].Pressed
+		v.events = v.events[1:]
+	}
+
+	return nil
+}
+
+//IsPressed returns true if the specified key is currently pressed.
+func (v *VirtualKeypad) IsPressed(key uint8) (bool, error) {
+	if key > 0xF {
+		return false, fmt.Errorf("key out of bounds: %x", key)
+	}
+	v.mux.RLock()
+	defer v.mux.RUnlock()
+	return v.keys[key], nil
+}
+
+//State returns the held keys as a bit mask, bit n set for key n.
+func (v *VirtualKeypad) State() uint16 {
+	v.mux.RLock()
+	defer v.mux.RUnlock()
+
+	var keys uint16
+	for k, pressed := range v.keys {
+		if pressed {
+			keys |= 1 << uint(k)
+		}
+	}
+	return keys
+}
+
+//SetState presses exactly the keys set in the bit mask.
+func (v *VirtualKeypad) SetState(keys uint16) error {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
+	for k := range v.keys {
+		v.keys[k] = keys&(1<<uint(k)) != 0
+	}
+	return nil
+}
+
+//WaitForKey returns the key from ScheduleWait if one is due, otherwise the lowest key currently pressed.
+//If nothing is pressed it skips ahead through the schedule to the next key press,
+//and returns an error if there isn't one, since nothing else can press a key.
+func (v *VirtualKeypad) WaitForKey() (uint8, error) {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
+	if len(v.waits) > 0 && v.waits[0].Cycle <= v.cycle {
+		key := v.waits[0].Key
+		v.waits = v.waits[1:]
+		return key, nil
+	}
+
+	for k, pressed := range v.keys {
+		if pressed {
+			return uint8(k), nil
+		}
+	}
+
+	for len(v.events) > 0 {
+		e := v.events[0]
+		v.events = v.events[1:]
+		v.keys[e.Key] = e.Pressed
+		if e.Cycle > v.cycle {
+			v.cycle = e.Cycle
+		}
+		if e.Pressed {
+			return e.Key, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no key pressed and no key presses scheduled")
+}
+
+//ParseKeyScript reads key events, one per line as "<cycle> press|release <key>" with the key in hex.
+//Blank lines and anything after a # are ignored.
+//
+//	# hold 5 for a second at 600 instructions per second
+//	1000 press 5
+//	1600 release 5
+func ParseKeyScript(r io.Reader) ([]KeyEvent, error) {
+	var events []KeyEvent
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected <cycle> press|release <key>, got %q", line, scanner.Text())
+		}
+
+		cycle, err := strconv.ParseUint(fields[0], 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid cycle %q", line, fields[0])
+		}
+		var pressed bool
+		switch strings.ToLower(fields[1]) {
+		case "press":
+			pressed = true
+		case "release":
+			pressed = false
+		default:
+			return nil, fmt.Errorf("line %d: expected press or release, got %q", line, fields[1])
+		}
+		key, err := parseKey(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		events = append(events, KeyEvent{Cycle: cycle, Key: key, Pressed: pressed})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read key script: %v", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,80 @@
+package chip8
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVirtualKeypadSchedule(t *testing.T) {
+	kp := NewVirtualKeypad()
+	kp.Schedule(10, 0x5, true)
+	kp.Schedule(20, 0x5, false)
+	kp.Schedule(10, 0xE, true)
+	kp.Schedule(10, 0xE, false)
+
+	tt := []struct {
+		cycle    uint64
+		key      uint8
+		expected bool
+	}{
+		{cycle: 9, key: 0x5, expected: false},
+		{cycle: 10, key: 0x5, expected: true},
+		//Press and release on the same cycle are applied in order.
+		{cycle: 10, key: 0xE, expected: false},
+		{cycle: 19, key: 0x5, expected: true},
+		{cycle: 25, key: 0x5, expected: false},
+	}
+
+	for _, tc := range tt {
+		err := kp.Update(tc.cycle)
+		if err != nil {
+			t.Fatalf("failed to update keypad: %v", err)
+		}
+		got, err := kp.IsPressed(tc.key)
+		if err != nil {
+			t.Fatalf("failed to read key %x: %v", tc.key, err)
+		}
+		if got != tc.expected {
+			t.Errorf("cycle %d key %x - expected: %v; got: %v", tc.cycle, tc.key, tc.expected, got)
+		}
+	}
+
+	if err := kp.Press(0x10); err == nil {
+		t.Errorf("expected key out of bounds error")
+	}
+}
+
+func TestParseKeyScript(t *testing.T) {
+	events, err := ParseKeyScript(strings.NewReader("# start\n100 press 5\n\n0x80 press a  # hex cycle\n200 RELEASE 5\n"))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	expected := []KeyEvent{{Cycle: 100, Key: 0x5, Pressed: true}, {Cycle: 0x80, Key: 0xA, Pressed: true}, {Cycle: 200, Key: 0x5}}
+	if len(events) != len(expected) {
+		t.Fatalf("expected: %v; got: %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("event %d - expected: %v; got: %v", i, expected[i], events[i])
+		}
+	}
+
+	tt := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{name: "Missing key", src: "100 press", expected: "line 1: expected <cycle> press|release <key>"},
+		{name: "Bad cycle", src: "\nsoon press 5", expected: `line 2: invalid cycle "soon"`},
+		{name: "Bad action", src: "100 hold 5", expected: `line 1: expected press or release, got "hold"`},
+		{name: "Bad key", src: "100 press G", expected: `line 1: "G" is not a key`},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseKeyScript(strings.NewReader(tc.src))
+			if err == nil || !strings.HasPrefix(err.Error(), tc.expected) {
+				t.Errorf("expected: %q; got: %v", tc.expected, err)
+			}
+		})
+	}
+}
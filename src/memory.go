@@ -2,15 +2,36 @@ package chip8
 
 import "fmt"
 
+const (
+	//MemorySize is the CHIP-8 and SUPER-CHIP address space, 12 bit addresses.
+	MemorySize = 0x1000
+	//ExtendedMemorySize is the XO-CHIP address space, 16 bit addresses.
+	ExtendedMemorySize = 0x10000
+)
+
 //Memory module
+//Holds 4KB unless extended to the XO-CHIP's 64KB.
 type Memory struct {
-	memory [4096]byte
+	memory   [ExtendedMemorySize]byte
+	extended bool
+}
+
+//SetExtended switches between 12 bit and 16 bit addressing.
+func (m *Memory) SetExtended(on bool) {
+	m.extended = on
+}
+
+//Size returns the number of addressable bytes.
+func (m *Memory) Size() int {
+	if m.extended {
+		return ExtendedMemorySize
+	}
+	return MemorySize
 }
 
-//Write writes a byte to a specified 12 bit address
+//Write writes a byte to a specified address, 12 bit unless the memory is extended.
 func (m *Memory) Write(data byte, addr uint16) error {
-	msb := addr & 0xF000
-	if msb != 0 {
+	if int(addr) >= m.Size() {
 		return fmt.Errorf("address out of bounds: %x", addr)
 	}
 	m.memory[addr] = data
@@ -18,12 +39,27 @@ func (m *Memory) Write(data byte, addr uint16) error {
 	return nil
 }
 
-//Read a byte from a specified 12 bit address
+//Read a byte from a specified address, 12 bit unless the memory is extended.
 func (m *Memory) Read(addr uint16) (byte, error) {
-	msb := addr & 0xF000
-	if msb != 0 {
+	if int(addr) >= m.Size() {
 		return 0, fmt.Errorf("address out of bounds: %x", addr)
 	}
 
 	return m.memory[addr], nil
 }
+
+//Snapshot returns a copy of the whole memory.
+func (m *Memory) Snapshot() []byte {
+	s := make([]byte, m.Size())
+	copy(s, m.memory[:])
+	return s
+}
+
+//Restore overwrites the whole memory with a snapshot.
+func (m *Memory) Restore(s []byte) error {
+	if len(s) != m.Size() {
+		return fmt.Errorf("memory snapshot is %d bytes, expected %d", len(s), m.Size())
+	}
+	copy(m.memory[:], s)
+	return nil
+}
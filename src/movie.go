@@ -0,0 +1,224 @@
+package chip8
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//Movie is a recording of the keys pressed while playing a program, enough to play the run back exactly.
+//Replays get the same settings and random numbers, and the timers are stepped by cycle count
+//rather than the clock, so the machine should end up in the same state as the recording.
+type Movie struct {
+	//ROM is the SHA-1 of the program, in hex.
+	ROM          string `json:"rom"`
+	Platform     string `json:"platform"`
+	Quirks       Quirks `json:"quirks"`
+	IPS          int    `json:"ips"`
+	TimerDivider uint64 `json:"timer_divider,omitempty"`
+	Seed         int64  `json:"seed"`
+
+	//Events are the key presses and releases, and the keys Fx0A got, in the order they happened.
+	Events []KeyEvent `json:"events"`
+
+	//Cycles is how long the recording ran for and Hash is StateHash at the end of it.
+	Cycles uint64 `json:"cycles"`
+	Hash   string `json:"hash"`
+}
+
+//errMovie refuses anything that would take the machine back to an earlier state during a movie.
+//The keys would be recorded at cycles that have already gone by, so the replay couldn't match.
+var errMovie = fmt.Errorf("save states and rewinding are off while a movie is recording or replaying")
+
+//movieKeypad passes everything through to the keypad it wraps, adding each change of keys to the movie.
+type movieKeypad struct {
+	Keypad
+	movie *Movie
+
+	cycle uint64
+	last  uint16
+}
+
+//Update passes the cycle on, then records any keys that changed since last time.
+func (k *movieKeypad) Update(cycle uint64) error {
+	err := k.Keypad.Update(cycle)
+	if err != nil {
+		return err
+	}
+	k.cycle = cycle
+	k.record(k.Keypad.State())
+	return nil
+}
+
+//WaitForKey records the key that was pressed, even if it's let go before the next Update,
+//and which key it returned. Other keys can be held at the same time, so the replay can't work it out.
+func (k *movieKeypad) WaitForKey() (uint8, error) {
+	key, err := k.Keypad.WaitForKey()
+	if err != nil {
+		return key, err
+	}
+	k.record(k.last | 1<<key)
+	k.movie.Events = append(k.movie.Events, KeyEvent{Cycle: k.cycle, Key: key, Wait: true})
+	return key, nil
+}
+
+//SetState passes the keys on and records them.
+func (k *movieKeypad) SetState(keys uint16) error {
+	err := k.Keypad.SetState(keys)
+	if err != nil {
+		return err
+	}
+	k.record(keys)
+	return nil
+}
+
+//record adds an event for every key that's different in keys, releases first.
+func (k *movieKeypad) record(keys uint16) {
+	changed := keys ^ k.last
+	for _, pressed := range []bool{false, true} {
+		for key := uint8(0); key < 16; key++ {
+			if changed&(1<<key) != 0 && keys&(1<<key) != 0 == pressed {
+				k.movie.Events = append(k.movie.Events, KeyEvent{Cycle: k.cycle, Key: key, Pressed: pressed})
+			}
+		}
+	}
+	k.last = keys
+}
+
+//StartMovie seeds the random numbers and starts recording key presses into a new movie.
+//Rewind is turned off and save states refused until EndMovie is called once the run is over.
+func (c *CPU) StartMovie(program []byte, seed int64) *Movie {
+	sum := sha1.Sum(program)
+	m := &Movie{
+		ROM:          hex.EncodeToString(sum[:]),
+		Platform:     c.Platform.String(),
+		Quirks:       c.Quirks,
+		IPS:          c.IPS,
+		TimerDivider: c.TimerDivider,
+		Seed:         seed,
+	}
+	c.Seed(seed)
+	c.Rewind = nil
+	c.movie = m
+
+	k := &movieKeypad{Keypad: c.Input, movie: m, cycle: c.Cycles}
+	k.record(c.Input.State())
+	c.Input = k
+	return m
+}
+
+//EndMovie stops recording key presses and fills in where the run ended.
+func (c *CPU) EndMovie(m *Movie) error {
+	if k, ok := c.Input.(*movieKeypad); ok && k.movie == m {
+		c.Input = k.Keypad
+	}
+	c.movie = nil
+	hash, err := c.StateHash()
+	if err != nil {
+		return err
+	}
+	m.Cycles = c.Cycles
+	m.Hash = hash
+	return nil
+}
+
+//PlayMovie sets the CPU up to replay a movie of the program: the same settings and random numbers,
+//the recorded key presses on a VirtualKeypad, and Until set to stop where the recording did.
+//The program must already be loaded, check the result with CheckMovie.
+//Like StartMovie, Rewind is turned off and save states refused.
+func (c *CPU) PlayMovie(m *Movie, program []byte) error {
+	sum := sha1.Sum(program)
+	if rom := hex.EncodeToString(sum[:]); rom != m.ROM {
+		return fmt.Errorf("movie was recorded with a different program: %s, got %s", m.ROM, rom)
+	}
+	p, err := ParsePlatform(m.Platform)
+	if err != nil {
+		return fmt.Errorf("could not read movie platform: %v", err)
+	}
+
+	in := NewVirtualKeypad()
+	for _, e := range m.Events {
+		if e.Wait {
+			err = in.ScheduleWait(e.Cycle, e.Key)
+		} else {
+			err = in.Schedule(e.Cycle, e.Key, e.Pressed)
+		}
+		if err != nil {
+			return fmt.Errorf("could not schedule key event: %v", err)
+		}
+	}
+
+	c.SetPlatform(p)
+	c.SetQuirks(m.Quirks)
+	c.IPS = m.IPS
+	c.TimerDivider = m.TimerDivider
+	c.Seed(m.Seed)
+	c.Input = in
+	c.Until = m.Cycles
+	c.Rewind = nil
+	c.movie = m
+	return nil
+}
+
+//CheckMovie returns an error if the replay didn't end up where the recording did.
+func (c *CPU) CheckMovie(m *Movie) error {
+	if c.Cycles != m.Cycles {
+		return fmt.Errorf("replay stopped after %d cycles, recording ran for %d", c.Cycles, m.Cycles)
+	}
+	hash, err := c.StateHash()
+	if err != nil {
+		return err
+	}
+	if hash != m.Hash {
+		return fmt.Errorf("replay ended in a different state: %s, recording ended in %s", hash, m.Hash)
+	}
+	return nil
+}
+
+//StateHash returns the SHA-1, in hex, of the registers, timers, memory and screen.
+func (c *CPU) StateHash() (string, error) {
+	s, err := c.State()
+	if err != nil {
+		return "", fmt.Errorf("could not get state: %v", err)
+	}
+
+	h := sha1.New()
+	binary.Write(h, binary.BigEndian, []uint16{s.PC, s.I, uint16(s.SP), uint16(s.DT), uint16(s.ST)})
+	binary.Write(h, binary.BigEndian, s.V)
+	binary.Write(h, binary.BigEndian, s.Stack)
+	binary.Write(h, binary.BigEndian, s.RPL)
+	h.Write(s.Memory)
+	binary.Write(h, binary.BigEndian, []uint16{uint16(s.Screen.W), uint16(s.Screen.H)})
+	h.Write(s.Screen.Pix)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+//SaveFile writes the movie to a JSON file.
+func (m *Movie) SaveFile(name string) error {
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return fmt.Errorf("could not encode movie: %v", err)
+	}
+	err = os.WriteFile(name, data, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write movie: %v", err)
+	}
+	return nil
+}
+
+//LoadMovie reads a movie written by SaveFile.
+func LoadMovie(name string) (*Movie, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not read movie: %v", err)
+	}
+	var m Movie
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode movie %s: %v", name, err)
+	}
+	return &m, nil
+}
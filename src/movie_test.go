@@ -0,0 +1,231 @@
+package chip8
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+//movieProgram counts the cycles key 5 is held in V2, with a new random number in V0 every loop.
+var movieProgram = []byte{
+	0x61, 0x05, // LD V1, 5
+	0xC0, 0xFF, // RND V0, 0xFF
+	0xE1, 0xA1, // SKNP V1
+	0x72, 0x01, // ADD V2, 1
+	0x12, 0x02, // JP 0x202
+}
+
+//recordMovie plays movieProgram for a few frames with key 5 pressed partway through.
+func recordMovie(t *testing.T, seed int64) *Movie {
+	c8 := setup()
+	err := c8.LoadProgram(movieProgram)
+	if err != nil {
+		t.Fatalf("failed to load program: %v", err)
+	}
+	keys := c8.Input.(*VirtualKeypad)
+	keys.Schedule(15, 0x5, true)
+	keys.Schedule(40, 0x5, false)
+
+	m := c8.StartMovie(movieProgram, seed)
+	for i := 0; i < 5; i++ {
+		res := c8.RunFrame()
+		if res.Err != nil {
+			t.Fatalf("failed to run frame: %v", res.Err)
+		}
+	}
+	err = c8.EndMovie(m)
+	if err != nil {
+		t.Fatalf("failed to end movie: %v", err)
+	}
+	return m
+}
+
+//replayMovie plays a movie back on a fresh CPU, returning the result of CheckMovie.
+func replayMovie(t *testing.T, m *Movie) error {
+	c8 := setup()
+	err := c8.LoadProgram(movieProgram)
+	if err != nil {
+		t.Fatalf("failed to load program: %v", err)
+	}
+	err = c8.PlayMovie(m, movieProgram)
+	if err != nil {
+		t.Fatalf("failed to play movie: %v", err)
+	}
+	for c8.Cycles < c8.Until {
+		res := c8.RunFrame()
+		if res.Err != nil {
+			t.Fatalf("failed to run frame: %v", res.Err)
+		}
+	}
+	return c8.CheckMovie(m)
+}
+
+func TestMovie(t *testing.T) {
+	m := recordMovie(t, 42)
+	expected := []KeyEvent{{Cycle: 15, Key: 0x5, Pressed: true}, {Cycle: 40, Key: 0x5, Pressed: false}}
+	if len(m.Events) != len(expected) {
+		t.Fatalf("expected events %v; got: %v", expected, m.Events)
+	}
+	for i := range expected {
+		if m.Events[i] != expected[i] {
+			t.Errorf("event %d - expected: %v; got: %v", i, expected[i], m.Events[i])
+		}
+	}
+
+	//Save and load it to check it survives the round trip.
+	name := filepath.Join(t.TempDir(), "run.movie")
+	err := m.SaveFile(name)
+	if err != nil {
+		t.Fatalf("failed to save movie: %v", err)
+	}
+	m, err = LoadMovie(name)
+	if err != nil {
+		t.Fatalf("failed to load movie: %v", err)
+	}
+
+	err = replayMovie(t, m)
+	if err != nil {
+		t.Errorf("replay should match: %v", err)
+	}
+
+	//Holding the key for longer changes how the run ends.
+	m.Events[1].Cycle = 45
+	err = replayMovie(t, m)
+	if err == nil {
+		t.Errorf("expected replay with different keys not to match")
+	}
+}
+
+func TestMovieSeed(t *testing.T) {
+	a := recordMovie(t, 1)
+	b := recordMovie(t, 1)
+	if a.Hash != b.Hash {
+		t.Errorf("same seed should give the same run: %s, %s", a.Hash, b.Hash)
+	}
+	c := recordMovie(t, 2)
+	if a.Hash == c.Hash {
+		t.Errorf("different seeds should give different random numbers")
+	}
+}
+
+func TestPlayMovieWrongProgram(t *testing.T) {
+	m := recordMovie(t, 42)
+	c8 := setup()
+	err := c8.PlayMovie(m, []byte{0x12, 0x00})
+	if err == nil {
+		t.Errorf("expected an error replaying a movie of another program")
+	}
+}
+
+//Rewinding partway through a recording is refused, so the replay still matches.
+func TestMovieRefusesGoingBack(t *testing.T) {
+	c8 := setup()
+	err := c8.LoadProgram(movieProgram)
+	if err != nil {
+		t.Fatalf("failed to load program: %v", err)
+	}
+	c8.Rewind = NewRewind(DefaultRewindFrames)
+	keys := c8.Input.(*VirtualKeypad)
+	keys.Schedule(15, 0x5, true)
+	keys.Schedule(40, 0x5, false)
+
+	m := c8.StartMovie(movieProgram, 42)
+	if c8.Rewind != nil {
+		t.Errorf("expected StartMovie to turn rewinding off")
+	}
+	for i := 0; i < 5; i++ {
+		res := c8.RunFrame()
+		if res.Err != nil {
+			t.Fatalf("failed to run frame: %v", res.Err)
+		}
+		if i == 2 {
+			//Put a buffer back, as if it was set after the movie started.
+			c8.Rewind = NewRewind(DefaultRewindFrames)
+			cycles := c8.Cycles
+			_, err = c8.StepBack(1)
+			if err == nil {
+				t.Errorf("expected rewinding during a recording to be refused")
+			}
+			if c8.Cycles != cycles {
+				t.Errorf("expected rewinding during a recording to be refused; went from cycle %d to %d", cycles, c8.Cycles)
+			}
+		}
+	}
+	err = c8.EndMovie(m)
+	if err != nil {
+		t.Fatalf("failed to end movie: %v", err)
+	}
+
+	err = replayMovie(t, m)
+	if err != nil {
+		t.Errorf("replay should match: %v", err)
+	}
+}
+
+//keyboardKeypad is a VirtualKeypad whose WaitForKey waits for a new key press like a keyboard,
+//rather than taking one already held.
+type keyboardKeypad struct {
+	*VirtualKeypad
+	next uint8
+}
+
+func (k *keyboardKeypad) WaitForKey() (uint8, error) {
+	return k.next, k.Press(k.next)
+}
+
+//The replay gets the key Fx0A got in the recording, even with a lower key held.
+func TestMovieWaitForKey(t *testing.T) {
+	//LD V1, K; JP 0x202
+	program := []byte{0xF1, 0x0A, 0x12, 0x02}
+	c8 := setup()
+	err := c8.LoadProgram(program)
+	if err != nil {
+		t.Fatalf("failed to load program: %v", err)
+	}
+	keys := &keyboardKeypad{VirtualKeypad: c8.Input.(*VirtualKeypad), next: 0x7}
+	keys.Press(0x2)
+	c8.Input = keys
+
+	m := c8.StartMovie(program, 42)
+	res := c8.RunFrame()
+	if res.Err != nil {
+		t.Fatalf("failed to run frame: %v", res.Err)
+	}
+	if c8.V[1] != 0x7 {
+		t.Fatalf("expected Fx0A to get key 7; got: %x", c8.V[1])
+	}
+	err = c8.EndMovie(m)
+	if err != nil {
+		t.Fatalf("failed to end movie: %v", err)
+	}
+
+	//Round trip it through a file like a real replay.
+	name := filepath.Join(t.TempDir(), "wait.movie")
+	err = m.SaveFile(name)
+	if err != nil {
+		t.Fatalf("failed to save movie: %v", err)
+	}
+	m, err = LoadMovie(name)
+	if err != nil {
+		t.Fatalf("failed to load movie: %v", err)
+	}
+
+	replay := setup()
+	err = replay.LoadProgram(program)
+	if err != nil {
+		t.Fatalf("failed to load program: %v", err)
+	}
+	err = replay.PlayMovie(m, program)
+	if err != nil {
+		t.Fatalf("failed to play movie: %v", err)
+	}
+	for replay.Cycles < replay.Until {
+		res := replay.RunFrame()
+		if res.Err != nil {
+			t.Fatalf("failed to run frame: %v", res.Err)
+		}
+	}
+	err = replay.CheckMovie(m)
+	if err != nil {
+		t.Errorf("replay should match: %v", err)
+	}
+}
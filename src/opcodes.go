@@ -0,0 +1,142 @@
+package chip8
+
+import (
+	"fmt"
+	"strings"
+)
+
+//Opcode describes a single instruction: how to recognise it, how it's written in assembly and its handler.
+//The table is shared by Decode, the disassembler and the assembler so they can't drift apart.
+type Opcode struct {
+	//Pattern is the instruction with all of its operand bits zeroed.
+	Pattern uint16
+	//Mask selects the bits of an instruction that must equal Pattern.
+	Mask uint16
+
+	Mnemonic string
+	//Operands in assembly order. "Vx" and "Vy" are the register fields, "nnn" an address,
+	//"kk" a byte, "n" a nibble and "x" a nibble in the x field. "long" is a 16 bit address
+	//in the word after the instruction, written "long 0x1234".
+	//Anything else is written literally, like "I" or "DT".
+	Operands []string
+	//Platform is the first platform to have the instruction.
+	Platform Platform
+
+	handler func(c *CPU, inst uint16) error
+}
+
+//opcodes is the CHIP-8 instruction set, Cowgod's mnemonics, along with the SUPER-CHIP and XO-CHIP additions.
+var opcodes = []Opcode{
+	{Pattern: 0x00E0, Mask: 0xFFFF, Mnemonic: "CLS", handler: (*CPU).ClearScreen},
+	{Pattern: 0x00EE, Mask: 0xFFFF, Mnemonic: "RET", handler: (*CPU).Return},
+	{Pattern: 0x00C0, Mask: 0xFFF0, Mnemonic: "SCD", Operands: []string{"n"}, Platform: PlatformSCHIP, handler: (*CPU).ScrollDown},
+	{Pattern: 0x00D0, Mask: 0xFFF0, Mnemonic: "SCU", Operands: []string{"n"}, Platform: PlatformXOCHIP, handler: (*CPU).ScrollUp},
+	{Pattern: 0x00FB, Mask: 0xFFFF, Mnemonic: "SCR", Platform: PlatformSCHIP, handler: (*CPU).ScrollRight},
+	{Pattern: 0x00FC, Mask: 0xFFFF, Mnemonic: "SCL", Platform: PlatformSCHIP, handler: (*CPU).ScrollLeft},
+	{Pattern: 0x00FD, Mask: 0xFFFF, Mnemonic: "EXIT", Platform: PlatformSCHIP, handler: (*CPU).Exit},
+	{Pattern: 0x00FE, Mask: 0xFFFF, Mnemonic: "LOW", Platform: PlatformSCHIP, handler: (*CPU).LowRes},
+	{Pattern: 0x00FF, Mask: 0xFFFF, Mnemonic: "HIGH", Platform: PlatformSCHIP, handler: (*CPU).HighRes},
+	{Pattern: 0x1000, Mask: 0xF000, Mnemonic: "JP", Operands: []string{"nnn"}, handler: (*CPU).Jump},
+	{Pattern: 0x2000, Mask: 0xF000, Mnemonic: "CALL", Operands: []string{"nnn"}, handler: (*CPU).Call},
+	{Pattern: 0x3000, Mask: 0xF000, Mnemonic: "SE", Operands: []string{"Vx", "kk"}, handler: (*CPU).SkipEqualVal},
+	{Pattern: 0x4000, Mask: 0xF000, Mnemonic: "SNE", Operands: []string{"Vx", "kk"}, handler: (*CPU).SkipNotEqualVal},
+	{Pattern: 0x5000, Mask: 0xF00F, Mnemonic: "SE", Operands: []string{"Vx", "Vy"}, handler: (*CPU).SkipEqualReg},
+	{Pattern: 0x5002, Mask: 0xF00F, Mnemonic: "SAVE", Operands: []string{"Vx", "Vy"}, Platform: PlatformXOCHIP, handler: (*CPU).SaveRange},
+	{Pattern: 0x5003, Mask: 0xF00F, Mnemonic: "LOAD", Operands: []string{"Vx", "Vy"}, Platform: PlatformXOCHIP, handler: (*CPU).LoadRange},
+	{Pattern: 0x6000, Mask: 0xF000, Mnemonic: "LD", Operands: []string{"Vx", "kk"}, handler: (*CPU).LoadValue},
+	{Pattern: 0x7000, Mask: 0xF000, Mnemonic: "ADD", Operands: []string{"Vx", "kk"}, handler: (*CPU).AddValue},
+	{Pattern: 0x8000, Mask: 0xF00F, Mnemonic: "LD", Operands: []string{"Vx", "Vy"}, handler: (*CPU).LoadReg},
+	{Pattern: 0x8001, Mask: 0xF00F, Mnemonic: "OR", Operands: []string{"Vx", "Vy"}, handler: (*CPU).Or},
+	{Pattern: 0x8002, Mask: 0xF00F, Mnemonic: "AND", Operands: []string{"Vx", "Vy"}, handler: (*CPU).And},
+	{Pattern: 0x8003, Mask: 0xF00F, Mnemonic: "XOR", Operands: []string{"Vx", "Vy"}, handler: (*CPU).Xor},
+	{Pattern: 0x8004, Mask: 0xF00F, Mnemonic: "ADD", Operands: []string{"Vx", "Vy"}, handler: (*CPU).Add},
+	{Pattern: 0x8005, Mask: 0xF00F, Mnemonic: "SUB", Operands: []string{"Vx", "Vy"}, handler: (*CPU).Sub},
+	{Pattern: 0x8006, Mask: 0xF00F, Mnemonic: "SHR", Operands: []string{"Vx", "Vy"}, handler: (*CPU).ShiftRight},
+	{Pattern: 0x8007, Mask: 0xF00F, Mnemonic: "SUBN", Operands: []string{"Vx", "Vy"}, handler: (*CPU).SubN},
+	{Pattern: 0x800E, Mask: 0xF00F, Mnemonic: "SHL", Operands: []string{"Vx", "Vy"}, handler: (*CPU).ShiftLeft},
+	{Pattern: 0x9000, Mask: 0xF00F, Mnemonic: "SNE", Operands: []string{"Vx", "Vy"}, handler: (*CPU).SkipNotEqualReg},
+	{Pattern: 0xA000, Mask: 0xF000, Mnemonic: "LD", Operands: []string{"I", "nnn"}, handler: (*CPU).LoadI},
+	{Pattern: 0xB000, Mask: 0xF000, Mnemonic: "JP", Operands: []string{"V0", "nnn"}, handler: (*CPU).JumpWithReg},
+	{Pattern: 0xC000, Mask: 0xF000, Mnemonic: "RND", Operands: []string{"Vx", "kk"}, handler: (*CPU).RandomAnd},
+	{Pattern: 0xD000, Mask: 0xF000, Mnemonic: "DRW", Operands: []string{"Vx", "Vy", "n"}, handler: (*CPU).DrawSprite},
+	{Pattern: 0xE09E, Mask: 0xF0FF, Mnemonic: "SKP", Operands: []string{"Vx"}, handler: (*CPU).SkipIfKey},
+	{Pattern: 0xE0A1, Mask: 0xF0FF, Mnemonic: "SKNP", Operands: []string{"Vx"}, handler: (*CPU).SkipIfNotKey},
+	{Pattern: 0xF007, Mask: 0xF0FF, Mnemonic: "LD", Operands: []string{"Vx", "DT"}, handler: (*CPU).SetRegDT},
+	{Pattern: 0xF00A, Mask: 0xF0FF, Mnemonic: "LD", Operands: []string{"Vx", "K"}, handler: (*CPU).WaitForKey},
+	{Pattern: 0xF015, Mask: 0xF0FF, Mnemonic: "LD", Operands: []string{"DT", "Vx"}, handler: (*CPU).SetDT},
+	{Pattern: 0xF018, Mask: 0xF0FF, Mnemonic: "LD", Operands: []string{"ST", "Vx"}, handler: (*CPU).SetST},
+	{Pattern: 0xF01E, Mask: 0xF0FF, Mnemonic: "ADD", Operands: []string{"I", "Vx"}, handler: (*CPU).AddIReg},
+	{Pattern: 0xF029, Mask: 0xF0FF, Mnemonic: "LD", Operands: []string{"F", "Vx"}, handler: (*CPU).SetISprite},
+	{Pattern: 0xF033, Mask: 0xF0FF, Mnemonic: "LD", Operands: []string{"B", "Vx"}, handler: (*CPU).SplitDecimal},
+	{Pattern: 0xF055, Mask: 0xF0FF, Mnemonic: "LD", Operands: []string{"[I]", "Vx"}, handler: (*CPU).StoreRegs},
+	{Pattern: 0xF065, Mask: 0xF0FF, Mnemonic: "LD", Operands: []string{"Vx", "[I]"}, handler: (*CPU).LoadRegs},
+	{Pattern: 0xF030, Mask: 0xF0FF, Mnemonic: "LD", Operands: []string{"HF", "Vx"}, Platform: PlatformSCHIP, handler: (*CPU).SetILargeSprite},
+	{Pattern: 0xF075, Mask: 0xF0FF, Mnemonic: "LD", Operands: []string{"R", "Vx"}, Platform: PlatformSCHIP, handler: (*CPU).StoreRPL},
+	{Pattern: 0xF085, Mask: 0xF0FF, Mnemonic: "LD", Operands: []string{"Vx", "R"}, Platform: PlatformSCHIP, handler: (*CPU).LoadRPL},
+	{Pattern: 0xF000, Mask: 0xFFFF, Mnemonic: "LD", Operands: []string{"I", "long"}, Platform: PlatformXOCHIP, handler: (*CPU).LoadILong},
+	{Pattern: 0xF001, Mask: 0xF0FF, Mnemonic: "PLANE", Operands: []string{"x"}, Platform: PlatformXOCHIP, handler: (*CPU).SelectPlanes},
+	{Pattern: 0xF002, Mask: 0xFFFF, Mnemonic: "AUDIO", Platform: PlatformXOCHIP, handler: (*CPU).LoadAudio},
+	{Pattern: 0xF03A, Mask: 0xF0FF, Mnemonic: "PITCH", Operands: []string{"Vx"}, Platform: PlatformXOCHIP, handler: (*CPU).SetPitch},
+}
+
+//Opcodes returns a copy of the instruction set table.
+func Opcodes() []Opcode {
+	ops := make([]Opcode, len(opcodes))
+	copy(ops, opcodes)
+	return ops
+}
+
+//LookupOpcode returns the entry in the instruction set table that matches the instruction.
+func LookupOpcode(inst uint16) (Opcode, bool) {
+	for _, op := range opcodes {
+		if inst&op.Mask == op.Pattern {
+			return op, true
+		}
+	}
+	return Opcode{}, false
+}
+
+//Size returns the length of the instruction in bytes, 4 if it has a long operand and 2 otherwise.
+func (o Opcode) Size() int {
+	for _, operand := range o.Operands {
+		if operand == "long" {
+			return 4
+		}
+	}
+	return 2
+}
+
+//Format returns the assembly for the instruction, e.g. "DRW V0, V1, 5".
+func (o Opcode) Format(inst uint16) string {
+	return o.FormatLong(inst, 0)
+}
+
+//FormatLong is Format for instructions with a long operand, which is passed in as long.
+func (o Opcode) FormatLong(inst uint16, long uint16) string {
+	if len(o.Operands) == 0 {
+		return o.Mnemonic
+	}
+
+	args := make([]string, len(o.Operands))
+	for i, operand := range o.Operands {
+		switch operand {
+		case "Vx":
+			args[i] = fmt.Sprintf("V%X", (inst&0x0F00)>>8)
+		case "Vy":
+			args[i] = fmt.Sprintf("V%X", (inst&0x00F0)>>4)
+		case "nnn":
+			args[i] = fmt.Sprintf("0x%03X", inst&0x0FFF)
+		case "kk":
+			args[i] = fmt.Sprintf("0x%02X", inst&0x00FF)
+		case "n":
+			args[i] = fmt.Sprintf("%d", inst&0x000F)
+		case "x":
+			args[i] = fmt.Sprintf("%d", (inst&0x0F00)>>8)
+		case "long":
+			args[i] = fmt.Sprintf("long 0x%04X", long)
+		default:
+			args[i] = operand
+		}
+	}
+	return o.Mnemonic + " " + strings.Join(args, ", ")
+}
@@ -0,0 +1,45 @@
+package chip8
+
+import "testing"
+
+func TestOpcodeTable(t *testing.T) {
+	for _, op := range Opcodes() {
+		got, ok := LookupOpcode(op.Pattern)
+		if !ok {
+			t.Errorf("%04X %s - not found", op.Pattern, op.Mnemonic)
+			continue
+		}
+		//An earlier entry with a looser mask would shadow this one.
+		if got.Pattern != op.Pattern {
+			t.Errorf("%04X %s - shadowed by %04X %s", op.Pattern, op.Mnemonic, got.Pattern, got.Mnemonic)
+		}
+		if op.Pattern&^op.Mask != 0 {
+			t.Errorf("%04X %s - pattern has bits outside the mask %04X", op.Pattern, op.Mnemonic, op.Mask)
+		}
+	}
+}
+
+func TestOpcodeFormat(t *testing.T) {
+	tt := []struct {
+		inst     uint16
+		expected string
+	}{
+		{inst: 0x00E0, expected: "CLS"},
+		{inst: 0x6120, expected: "LD V1, 0x20"},
+		{inst: 0xD015, expected: "DRW V0, V1, 5"},
+		{inst: 0xA2F0, expected: "LD I, 0x2F0"},
+		{inst: 0xFA33, expected: "LD B, VA"},
+		{inst: 0x00C4, expected: "SCD 4"},
+		{inst: 0xF330, expected: "LD HF, V3"},
+	}
+
+	for _, tc := range tt {
+		op, ok := LookupOpcode(tc.inst)
+		if !ok {
+			t.Fatalf("%04X - not found", tc.inst)
+		}
+		if got := op.Format(tc.inst); got != tc.expected {
+			t.Errorf("%04X - expected: %q; got: %q", tc.inst, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package chip8
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+)
+
+//Palette is the colour of each pixel value: unlit, plane 1, plane 2 and lit in both planes.
+//Only XO-CHIP programs draw to plane 2, so the last two colours are unused by everything else.
+type Palette [4]color.RGBA
+
+//DefaultPalette is the name of the palette used unless another is picked.
+const DefaultPalette = "classic"
+
+var palettes = map[string]Palette{
+	"classic": {rgb(0x000000), rgb(0xFFFFFF), rgb(0xAAAAAA), rgb(0x555555)},
+	"green":   {rgb(0x001100), rgb(0x33FF33), rgb(0x119911), rgb(0xAAFFAA)},
+	"amber":   {rgb(0x1A0F00), rgb(0xFFB000), rgb(0xB36B00), rgb(0xFFD780)},
+	"lcd":     {rgb(0x9BBC0F), rgb(0x0F380F), rgb(0x8BAC0F), rgb(0x306230)},
+	"octo":    {rgb(0x996600), rgb(0xFFCC00), rgb(0xFF6600), rgb(0x662200)},
+}
+
+func rgb(v uint32) color.RGBA {
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}
+}
+
+//LookupPalette returns the named palette, see PaletteNames.
+func LookupPalette(name string) (Palette, error) {
+	p, ok := palettes[strings.ToLower(name)]
+	if !ok {
+		return Palette{}, fmt.Errorf("unknown palette %q, expected one of: %s", name, strings.Join(PaletteNames(), ", "))
+	}
+	return p, nil
+}
+
+//PaletteNames returns the names of the palettes, sorted.
+func PaletteNames() []string {
+	var names []string
+	for name := range palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,20 @@
+package chip8
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestLookupPalette(t *testing.T) {
+	p, err := LookupPalette("Amber")
+	if err != nil {
+		t.Fatalf("failed to look up palette: %v", err)
+	}
+	if p[1] != (color.RGBA{R: 0xFF, G: 0xB0, B: 0x00, A: 0xFF}) {
+		t.Errorf("amber foreground - got: %v", p[1])
+	}
+	_, err = LookupPalette("purple")
+	if err == nil {
+		t.Errorf("expected an error for an unknown palette")
+	}
+}
@@ -0,0 +1,42 @@
+package chip8
+
+import (
+	"fmt"
+	"strings"
+)
+
+//Platform is the CHIP-8 variant a ROM was written for, it decides which instructions the CPU accepts.
+//Each platform is a superset of the ones before it.
+type Platform int
+
+const (
+	//PlatformCHIP8 is the original COSMAC VIP instruction set.
+	PlatformCHIP8 Platform = iota
+	//PlatformSCHIP is SUPER-CHIP 1.1, adding the 128x64 hi-res mode, scrolling and 16x16 sprites.
+	PlatformSCHIP
+	//PlatformXOCHIP is Octo's XO-CHIP, adding 64KB of memory, a second drawing plane and audio patterns.
+	PlatformXOCHIP
+)
+
+var platformNames = map[Platform]string{
+	PlatformCHIP8:  "chip8",
+	PlatformSCHIP:  "schip",
+	PlatformXOCHIP: "xochip",
+}
+
+func (p Platform) String() string {
+	if name, ok := platformNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Platform(%d)", int(p))
+}
+
+//ParsePlatform returns the platform with the given name: "chip8", "schip" or "xochip".
+func ParsePlatform(s string) (Platform, error) {
+	for p, name := range platformNames {
+		if strings.EqualFold(s, name) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown platform %q", s)
+}
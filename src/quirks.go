@@ -0,0 +1,61 @@
+package chip8
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//Quirks are the behaviours that differ between CHIP-8 interpreters.
+//The zero value is this emulator's original behaviour, which matches none of the presets exactly.
+type Quirks struct {
+	//ShiftVy makes 8xy6 and 8xyE shift Vy into Vx, otherwise Vx is shifted in place.
+	ShiftVy bool
+	//LoadStoreIncrementI makes Fx55 and Fx65 leave I pointing after the last register, otherwise I is left alone.
+	LoadStoreIncrementI bool
+	//LoadStoreIncrementIByX makes Fx55 and Fx65 leave I pointing at the last register, one short of
+	//LoadStoreIncrementI, which is how CHIP-48 got it wrong. LoadStoreIncrementI wins if both are set.
+	LoadStoreIncrementIByX bool
+	//JumpVx makes Bxnn jump to xnn plus Vx, otherwise Bnnn jumps to nnn plus V0.
+	JumpVx bool
+	//Clip cuts sprites off at the edge of the screen, otherwise they wrap around to the other side.
+	Clip bool
+	//VFReset zeroes VF after 8xy1, 8xy2 and 8xy3.
+	VFReset bool
+	//DisplayWait allows one sprite per frame, later draws wait for the next frame like the VIP waiting for vertical blank.
+	DisplayWait bool
+}
+
+var (
+	//QuirksVIP is the original COSMAC VIP interpreter.
+	QuirksVIP = Quirks{ShiftVy: true, LoadStoreIncrementI: true, Clip: true, VFReset: true, DisplayWait: true}
+	//QuirksCHIP48 is CHIP-48 on the HP-48 calculators.
+	QuirksCHIP48 = Quirks{LoadStoreIncrementIByX: true, JumpVx: true, Clip: true}
+	//QuirksSCHIP is SUPER-CHIP 1.1.
+	QuirksSCHIP = Quirks{JumpVx: true, Clip: true}
+)
+
+var quirkPresets = map[string]Quirks{
+	"vip":    QuirksVIP,
+	"chip48": QuirksCHIP48,
+	"schip":  QuirksSCHIP,
+}
+
+//LookupQuirks returns the named preset: "vip", "chip48" or "schip".
+func LookupQuirks(name string) (Quirks, error) {
+	q, ok := quirkPresets[strings.ToLower(name)]
+	if !ok {
+		return Quirks{}, fmt.Errorf("unknown quirks preset %q, expected one of: %s", name, strings.Join(QuirksPresets(), ", "))
+	}
+	return q, nil
+}
+
+//QuirksPresets returns the names of the presets, sorted.
+func QuirksPresets() []string {
+	var names []string
+	for name := range quirkPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,88 @@
+package chip8
+
+import "testing"
+
+func TestQuirks(t *testing.T) {
+	tt := []struct {
+		name    string
+		quirks  Quirks
+		program []byte
+		steps   int
+		reg     []treg
+		i       uint16
+		pc      uint16
+		lit     [][2]int32
+		unlit   [][2]int32
+	}{
+		{name: "Shift Vx in place", program: []byte{0x60, 0x04, 0x61, 0x10, 0x80, 0x16}, steps: 3,
+			reg: []treg{{reg: 0x0, value: 2}, {reg: 0x1, value: 0x10}}},
+		{name: "Shift Vy into Vx", quirks: Quirks{ShiftVy: true}, program: []byte{0x60, 0x04, 0x61, 0x10, 0x80, 0x16}, steps: 3,
+			reg: []treg{{reg: 0x0, value: 8}, {reg: 0x1, value: 0x10}}},
+		{name: "Load and store leave I", program: []byte{0xA3, 0x00, 0xF2, 0x55, 0xF2, 0x65}, steps: 3, i: 0x300},
+		{name: "Load and store increment I", quirks: Quirks{LoadStoreIncrementI: true}, program: []byte{0xA3, 0x00, 0xF2, 0x55, 0xF2, 0x65}, steps: 3, i: 0x306},
+		{name: "Load and store increment I by x", quirks: Quirks{LoadStoreIncrementIByX: true}, program: []byte{0xA3, 0x00, 0xF2, 0x55, 0xF2, 0x65}, steps: 3, i: 0x304},
+		{name: "Jump with V0", program: []byte{0x60, 0x02, 0x63, 0x04, 0xB3, 0x00}, steps: 3, pc: 0x302},
+		{name: "Jump with Vx", quirks: Quirks{JumpVx: true}, program: []byte{0x60, 0x02, 0x63, 0x04, 0xB3, 0x00}, steps: 3, pc: 0x304},
+		{name: "Logic leaves VF", program: []byte{0x6F, 0x05, 0x80, 0x11}, steps: 2, reg: []treg{{reg: 0xF, value: 5}}},
+		{name: "Logic resets VF", quirks: Quirks{VFReset: true}, program: []byte{0x6F, 0x05, 0x80, 0x11}, steps: 2, reg: []treg{{reg: 0xF, value: 0}}},
+		{name: "Sprites wrap", program: []byte{0x60, 0x3E, 0xD0, 0x11}, steps: 2, lit: [][2]int32{{62, 0}, {1, 0}}},
+		{name: "Sprites clip", quirks: Quirks{Clip: true}, program: []byte{0x60, 0x3E, 0xD0, 0x11}, steps: 2, lit: [][2]int32{{62, 0}}, unlit: [][2]int32{{0, 0}, {1, 0}}},
+		{name: "Sprite position wraps when clipping", quirks: Quirks{Clip: true}, program: []byte{0x60, 0x42, 0xD0, 0x11}, steps: 2, lit: [][2]int32{{2, 0}, {5, 0}}},
+		{name: "Draws run back to back", program: []byte{0xD0, 0x01, 0x61, 0x08, 0xD1, 0x01}, steps: 3, lit: [][2]int32{{0, 0}, {8, 0}}},
+		{name: "Draws wait for the next frame", quirks: Quirks{DisplayWait: true}, program: []byte{0xD0, 0x01, 0x61, 0x08, 0xD1, 0x01}, steps: 3, pc: 0x204, lit: [][2]int32{{0, 0}}, unlit: [][2]int32{{8, 0}}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			c8.Init()
+			c8.SetQuirks(tc.quirks)
+			err := c8.LoadProgram(tc.program)
+			if err != nil {
+				t.Fatalf("failed to load program: %v", err)
+			}
+			res := c8.RunCycles(tc.steps)
+			if res.Err != nil {
+				t.Fatalf("failed to run: %v", res.Err)
+			}
+			for _, r := range tc.reg {
+				if c8.V[r.reg] != r.value {
+					t.Errorf("V[%x] - expected: %d; got: %d", r.reg, r.value, c8.V[r.reg])
+				}
+			}
+			if tc.i != 0 && c8.I != tc.i {
+				t.Errorf("I - expected: %x; got: %x", tc.i, c8.I)
+			}
+			if tc.pc != 0 && c8.PC != tc.pc {
+				t.Errorf("PC - expected: %x; got: %x", tc.pc, c8.PC)
+			}
+			for _, p := range tc.lit {
+				if v, _ := c8.G.Pixel(p[0], p[1]); v != 1 {
+					t.Errorf("expected pixel (%d, %d) to be lit", p[0], p[1])
+				}
+			}
+			for _, p := range tc.unlit {
+				if v, _ := c8.G.Pixel(p[0], p[1]); v != 0 {
+					t.Errorf("expected pixel (%d, %d) to be unlit", p[0], p[1])
+				}
+			}
+		})
+	}
+}
+
+func TestLookupQuirks(t *testing.T) {
+	q, err := LookupQuirks("VIP")
+	if err != nil {
+		t.Fatalf("failed to look up preset: %v", err)
+	}
+	if q != QuirksVIP {
+		t.Errorf("expected: %+v; got: %+v", QuirksVIP, q)
+	}
+	if QuirksCHIP48 == QuirksSCHIP {
+		t.Errorf("expected the CHIP-48 and SCHIP presets to differ")
+	}
+	_, err = LookupQuirks("octo")
+	if err == nil {
+		t.Errorf("expected an error for an unknown preset")
+	}
+}
@@ -0,0 +1,59 @@
+package chip8
+
+//RandomSource supplies the random bytes for Cxkk.
+type RandomSource interface {
+	Byte() uint8
+}
+
+//SeededRandom is a small random number generator (SplitMix64) whose whole state is one number,
+//so it can be put in save states and the same seed always gives the same bytes.
+type SeededRandom struct {
+	state uint64
+}
+
+//NewSeededRandom returns a generator started from the seed.
+func NewSeededRandom(seed int64) *SeededRandom {
+	return &SeededRandom{state: uint64(seed)}
+}
+
+//Byte returns the next random byte.
+func (r *SeededRandom) Byte() uint8 {
+	r.state += 0x9E3779B97F4A7C15
+	z := r.state
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+	z ^= z >> 31
+	return uint8(z >> 56)
+}
+
+//State returns the generator's position, SetState goes back to it.
+func (r *SeededRandom) State() uint64 {
+	return r.state
+}
+
+//SetState puts the generator back to a position returned by State.
+func (r *SeededRandom) SetState(state uint64) {
+	r.state = state
+}
+
+//ScriptedRandom returns the bytes in Bytes in order, starting over once it runs out.
+//Used by tests that need to know what Cxkk will get.
+type ScriptedRandom struct {
+	Bytes []uint8
+	next  int
+}
+
+//NewScriptedRandom returns a source that plays back the bytes.
+func NewScriptedRandom(b ...uint8) *ScriptedRandom {
+	return &ScriptedRandom{Bytes: b}
+}
+
+//Byte returns the next byte in the script, or 0 if the script is empty.
+func (r *ScriptedRandom) Byte() uint8 {
+	if len(r.Bytes) == 0 {
+		return 0
+	}
+	b := r.Bytes[r.next%len(r.Bytes)]
+	r.next++
+	return b
+}
@@ -0,0 +1,46 @@
+package chip8
+
+import (
+	"testing"
+)
+
+func TestSeededRandom(t *testing.T) {
+	a := NewSeededRandom(1)
+	b := NewSeededRandom(1)
+	c := NewSeededRandom(2)
+
+	differs := false
+	for i := 0; i < 64; i++ {
+		x := a.Byte()
+		if y := b.Byte(); x != y {
+			t.Fatalf("byte %d - same seed gave %x and %x", i, x, y)
+		}
+		if c.Byte() != x {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Errorf("expected a different seed to give different bytes")
+	}
+
+	//Going back to a saved position repeats the bytes from there.
+	state := a.State()
+	first := a.Byte()
+	a.SetState(state)
+	if got := a.Byte(); got != first {
+		t.Errorf("expected: %x; got: %x", first, got)
+	}
+}
+
+func TestScriptedRandom(t *testing.T) {
+	r := NewScriptedRandom(1, 2, 3)
+	expected := []uint8{1, 2, 3, 1}
+	for i, e := range expected {
+		if got := r.Byte(); got != e {
+			t.Errorf("byte %d - expected: %d; got: %d", i, e, got)
+		}
+	}
+	if got := NewScriptedRandom().Byte(); got != 0 {
+		t.Errorf("empty script - expected: 0; got: %d", got)
+	}
+}
@@ -0,0 +1,189 @@
+package chip8
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+)
+
+//minGIFDelay is the shortest delay a GIF image gets, in 100ths of a second.
+//Viewers slow anything shorter down to 10, so faster changes are merged.
+const minGIFDelay = 2
+
+//Recorder is a FrameTap that records the screen to an animated GIF between Start and Stop.
+type Recorder struct {
+	Palette Palette
+	//Limit stops the recording once it's this many frames long, zero to record until Stop.
+	Limit int
+
+	name      string
+	file      *os.File
+	recording bool
+	anim      *gif.GIF
+	//frames is the number of frames recorded, last is the latest one and lastAt the frame its image started on.
+	frames int
+	last   Frame
+	lastAt int
+}
+
+//NewRecorder returns a recorder using the palette's colours, it doesn't record until Start.
+func NewRecorder(p Palette) *Recorder {
+	return &Recorder{Palette: p}
+}
+
+//Start begins a new recording, written to name by Stop.
+//The file is created straight away, so a path that can't be written to fails here rather than at Stop.
+func (r *Recorder) Start(name string) error {
+	if r.recording {
+		return fmt.Errorf("already recording to %s", r.name)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("could not create recording: %v", err)
+	}
+	r.name = name
+	r.file = f
+	r.recording = true
+	r.anim = &gif.GIF{}
+	r.frames = 0
+	r.last = Frame{}
+	r.lastAt = 0
+	return nil
+}
+
+//Recording returns true between Start and Stop.
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+//Name returns the file the current or last recording is written to.
+func (r *Recorder) Name() string {
+	return r.name
+}
+
+//TapFrame adds the frame to the recording, stopping it once it reaches Limit.
+//Frames the same as the one before just make it stay on screen for longer, a change that comes before
+//the latest image has been shown for minGIFDelay replaces it.
+func (r *Recorder) TapFrame(n uint64, frame Frame) error {
+	if !r.recording {
+		return nil
+	}
+
+	if r.frames == 0 || !sameFrame(frame, r.last) {
+		if r.frames > 0 && centiseconds(r.frames)-centiseconds(r.lastAt) < minGIFDelay {
+			r.anim.Image[len(r.anim.Image)-1] = r.paletted(frame)
+		} else {
+			r.finishImage()
+			r.anim.Image = append(r.anim.Image, r.paletted(frame))
+			r.anim.Delay = append(r.anim.Delay, 0)
+			r.lastAt = r.frames
+		}
+		r.last = Frame{W: frame.W, H: frame.H, Pix: append([]uint8(nil), frame.Pix...)}
+	}
+	r.frames++
+
+	if r.Limit > 0 && r.frames >= r.Limit {
+		return r.Stop()
+	}
+	return nil
+}
+
+//Stop ends the recording and writes the GIF.
+func (r *Recorder) Stop() error {
+	if !r.recording {
+		return fmt.Errorf("not recording")
+	}
+	r.recording = false
+	f := r.file
+	r.file = nil
+	if r.frames == 0 {
+		f.Close()
+		os.Remove(r.name)
+		return fmt.Errorf("no frames recorded")
+	}
+	r.finishImage()
+	//The last image can be too short too, add its time to the one before.
+	if n := len(r.anim.Delay); n > 1 && r.anim.Delay[n-1] < minGIFDelay {
+		r.anim.Delay[n-2] += r.anim.Delay[n-1]
+		r.anim.Delay = r.anim.Delay[:n-1]
+		r.anim.Image = r.anim.Image[:n-1]
+	}
+
+	err := gif.EncodeAll(f, r.anim)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("could not encode gif: %v", err)
+	}
+	r.anim = nil
+	return f.Close()
+}
+
+//finishImage sets the delay of the latest image now it's known how many frames it was shown for.
+//GIF delays are in 100ths of a second, so it's worked out from the time since the recording started
+//to keep the rounding from adding up.
+func (r *Recorder) finishImage() {
+	if len(r.anim.Delay) == 0 {
+		return
+	}
+	r.anim.Delay[len(r.anim.Delay)-1] = centiseconds(r.frames) - centiseconds(r.lastAt)
+}
+
+//centiseconds returns how long frames take at 60Hz in 100ths of a second, rounded.
+func centiseconds(frames int) int {
+	return (frames*100 + FrameRate/2) / FrameRate
+}
+
+//paletted draws the frame in the recorder's colours, scaled the same as screenshots.
+func (r *Recorder) paletted(frame Frame) *image.Paletted {
+	colours := make(color.Palette, len(r.Palette))
+	for i, c := range r.Palette {
+		colours[i] = c
+	}
+	scale := screenshotScale(frame.W)
+	img := image.NewPaletted(image.Rect(0, 0, frame.W*scale, frame.H*scale), colours)
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			img.SetColorIndex(x, y, frame.At(x/scale, y/scale)&3)
+		}
+	}
+	return img
+}
+
+//sameFrame returns true if a and b are the same size with the same pixels.
+func sameFrame(a Frame, b Frame) bool {
+	if a.W != b.W || a.H != b.H || len(a.Pix) != len(b.Pix) {
+		return false
+	}
+	for i := range a.Pix {
+		if a.Pix[i] != b.Pix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//ToggleRecording starts recording a numbered GIF, or stops and writes the one being recorded.
+func (c *CPU) ToggleRecording() error {
+	if c.Recorder == nil {
+		c.Recorder = NewRecorder(c.palette())
+		c.Taps = append(c.Taps, c.Recorder)
+	}
+	if c.Recorder.Recording() {
+		err := c.Recorder.Stop()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved recording to %s\n", c.Recorder.Name())
+		return nil
+	}
+
+	c.Recorder.Palette = c.palette()
+	err := c.Recorder.Start(nextName(c.ScreenshotPath, ".gif"))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recording to %s\n", c.Recorder.Name())
+	return nil
+}
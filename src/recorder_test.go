@@ -0,0 +1,126 @@
+package chip8
+
+import (
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "clip.gif")
+	r := NewRecorder(palettes[DefaultPalette])
+	r.Limit = 6
+	err := r.Start(name)
+	if err != nil {
+		t.Fatalf("failed to start recording: %v", err)
+	}
+
+	a := testFrame()
+	b := testFrame()
+	b.Pix[2] = 1
+	for i, frame := range []Frame{a, a, b, b, b, a} {
+		err = r.TapFrame(uint64(i+1), frame)
+		if err != nil {
+			t.Fatalf("failed to record frame %d: %v", i, err)
+		}
+	}
+	if r.Recording() {
+		t.Fatalf("expected the recording to stop at the limit")
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("failed to open recording: %v", err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatalf("failed to decode recording: %v", err)
+	}
+
+	//6 frames at 60Hz is a tenth of a second, split between the three changes of screen.
+	expected := []int{3, 5, 2}
+	if len(anim.Delay) != len(expected) {
+		t.Fatalf("expected delays %v; got: %v", expected, anim.Delay)
+	}
+	for i := range expected {
+		if anim.Delay[i] != expected[i] {
+			t.Errorf("image %d - expected delay: %d; got: %d", i, expected[i], anim.Delay[i])
+		}
+	}
+	if got := anim.Image[1].ColorIndexAt(2*ScreenshotScale, 0); got != 1 {
+		t.Errorf("expected the second image to have pixel 2 lit; got: %d", got)
+	}
+}
+
+func TestRecorderNotStarted(t *testing.T) {
+	r := NewRecorder(palettes[DefaultPalette])
+	err := r.TapFrame(1, testFrame())
+	if err != nil {
+		t.Errorf("expected frames to be ignored before Start; got: %v", err)
+	}
+	err = r.Stop()
+	if err == nil {
+		t.Errorf("expected an error stopping a recording that never started")
+	}
+}
+
+//A recording that can't be written fails when it's started, not once it's over.
+func TestRecorderBadPath(t *testing.T) {
+	r := NewRecorder(palettes[DefaultPalette])
+	err := r.Start(filepath.Join(t.TempDir(), "missing", "clip.gif"))
+	if err == nil {
+		t.Fatalf("expected an error starting a recording in a missing directory")
+	}
+	if r.Recording() {
+		t.Errorf("expected not to be recording")
+	}
+}
+
+//A screen that changes every frame is merged into images of at least minGIFDelay,
+//which still add up to the time recorded.
+func TestRecorderFastChanges(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "fast.gif")
+	r := NewRecorder(palettes[DefaultPalette])
+	err := r.Start(name)
+	if err != nil {
+		t.Fatalf("failed to start recording: %v", err)
+	}
+
+	frames := 61
+	for i := 0; i < frames; i++ {
+		frame := testFrame()
+		frame.Pix[i%len(frame.Pix)] ^= 1
+		err = r.TapFrame(uint64(i+1), frame)
+		if err != nil {
+			t.Fatalf("failed to record frame %d: %v", i, err)
+		}
+	}
+	err = r.Stop()
+	if err != nil {
+		t.Fatalf("failed to stop recording: %v", err)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("failed to open recording: %v", err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatalf("failed to decode recording: %v", err)
+	}
+
+	total := 0
+	for i, d := range anim.Delay {
+		if d < minGIFDelay {
+			t.Errorf("image %d - expected a delay of at least %d; got: %d", i, minGIFDelay, d)
+		}
+		total += d
+	}
+	//61 frames at 60Hz is just over a second.
+	if total != 102 {
+		t.Errorf("expected the delays to add up to 102; got: %d from %v", total, anim.Delay)
+	}
+}
@@ -0,0 +1,146 @@
+package chip8
+
+import (
+	"fmt"
+)
+
+//DefaultRewindFrames is how many frames of history Run keeps for rewinding, 10 seconds.
+const DefaultRewindFrames = 10 * FrameRate
+
+//Rewind is a ring buffer of the machine's state over the last few frames.
+//Only the newest state is kept whole, each older one is stored as the bytes of memory and screen
+//that differ from the state after it, which is usually only a handful.
+type Rewind struct {
+	entries []rewindEntry
+	//next is where the next entry goes, count how many of the entries are in use.
+	next  int
+	count int
+
+	//cur is the newest state captured.
+	cur *State
+}
+
+//rewindEntry turns a state back into the one captured the frame before.
+type rewindEntry struct {
+	//state is the earlier state without its Memory and Screen.Pix, which are in the patches.
+	state  State
+	memory delta
+	screen delta
+}
+
+//delta holds what's needed to turn one byte slice back into an earlier one.
+type delta struct {
+	//size is the length of the earlier slice.
+	size    int
+	patches []patch
+}
+
+//patch is a run of bytes from the earlier slice.
+type patch struct {
+	offset int
+	data   []byte
+}
+
+//NewRewind returns a rewind buffer holding up to frames steps back.
+func NewRewind(frames int) *Rewind {
+	return &Rewind{entries: make([]rewindEntry, frames)}
+}
+
+//Len returns how many frames it's possible to step back.
+func (r *Rewind) Len() int {
+	return r.count
+}
+
+//Capture adds the machine's state to the buffer, dropping the oldest state if it's full.
+func (r *Rewind) Capture(c *CPU) error {
+	s, err := c.State()
+	if err != nil {
+		return fmt.Errorf("could not capture state: %v", err)
+	}
+	if r.cur != nil && len(r.entries) > 0 {
+		prev := *r.cur
+		e := rewindEntry{
+			memory: diff(prev.Memory, s.Memory),
+			screen: diff(prev.Screen.Pix, s.Screen.Pix),
+		}
+		prev.Memory = nil
+		prev.Screen.Pix = nil
+		e.state = prev
+
+		r.entries[r.next] = e
+		r.next = (r.next + 1) % len(r.entries)
+		if r.count < len(r.entries) {
+			r.count++
+		}
+	}
+	r.cur = s
+	return nil
+}
+
+//Back steps the machine back n frames, or as far as the buffer goes.
+//Returns how many frames it went back.
+func (r *Rewind) Back(c *CPU, n int) (int, error) {
+	steps := 0
+	for ; steps < n && r.count > 0; steps++ {
+		r.next = (r.next - 1 + len(r.entries)) % len(r.entries)
+		r.count--
+		e := r.entries[r.next]
+		r.entries[r.next] = rewindEntry{}
+
+		prev := e.state
+		prev.Memory = e.memory.apply(r.cur.Memory)
+		prev.Screen.Pix = e.screen.apply(r.cur.Screen.Pix)
+		r.cur = &prev
+	}
+	if steps == 0 {
+		return 0, nil
+	}
+
+	//Restore gets a copy, so running on from here doesn't change the buffer.
+	s := *r.cur
+	s.Memory = append([]byte(nil), r.cur.Memory...)
+	s.Screen.Pix = append([]uint8(nil), r.cur.Screen.Pix...)
+	err := c.Restore(&s)
+	if err != nil {
+		return steps, fmt.Errorf("could not restore state: %v", err)
+	}
+	return steps, nil
+}
+
+//StepBack steps the machine back n frames with Rewind, returning how many it went back.
+//Refused while a movie is recording or replaying, the movie has no way to go back in time.
+func (c *CPU) StepBack(n int) (int, error) {
+	if c.movie != nil {
+		return 0, errMovie
+	}
+	if c.Rewind == nil {
+		return 0, fmt.Errorf("rewinding is off")
+	}
+	return c.Rewind.Back(c, n)
+}
+
+//diff returns the delta that turns later back into earlier.
+func diff(earlier []byte, later []byte) delta {
+	d := delta{size: len(earlier)}
+	for i := 0; i < len(earlier); i++ {
+		if i < len(later) && earlier[i] == later[i] {
+			continue
+		}
+		start := i
+		for i < len(earlier) && (i >= len(later) || earlier[i] != later[i]) {
+			i++
+		}
+		d.patches = append(d.patches, patch{offset: start, data: append([]byte(nil), earlier[start:i]...)})
+	}
+	return d
+}
+
+//apply returns a new slice holding the earlier bytes.
+func (d delta) apply(later []byte) []byte {
+	earlier := make([]byte, d.size)
+	copy(earlier, later)
+	for _, p := range d.patches {
+		copy(earlier[p.offset:], p.data)
+	}
+	return earlier
+}
@@ -0,0 +1,103 @@
+package chip8
+
+import (
+	"testing"
+)
+
+func TestRewind(t *testing.T) {
+	c8 := setup()
+	c8.Init()
+	c8.IPS = 7 * FrameRate
+	c8.Rewind = NewRewind(5)
+	//Count V0 up, draw it as a digit and store it in memory, one loop a frame.
+	err := c8.LoadProgram([]byte{
+		0x00, 0xE0, // CLS
+		0xF0, 0x29, // LD F, V0
+		0xD1, 0x15, // DRW V1, V1, 5
+		0xA3, 0x00, // LD I, 0x300
+		0xF0, 0x55, // LD [I], V0
+		0x70, 0x01, // ADD V0, 1
+		0x12, 0x00, // JP 0x200
+	})
+	if err != nil {
+		t.Fatalf("failed to load program: %v", err)
+	}
+	c8.Seed(1)
+
+	var hashes []string
+	for i := 0; i < 8; i++ {
+		res := c8.RunFrame()
+		if res.Err != nil {
+			t.Fatalf("failed to run frame: %v", res.Err)
+		}
+		hash, _ := c8.StateHash()
+		hashes = append(hashes, hash)
+	}
+
+	if c8.Rewind.Len() != 5 {
+		t.Fatalf("expected the buffer to hold 5 frames; got: %d", c8.Rewind.Len())
+	}
+
+	n, err := c8.Rewind.Back(c8, 2)
+	if err != nil || n != 2 {
+		t.Fatalf("failed to step back 2 frames: %d, %v", n, err)
+	}
+	if hash, _ := c8.StateHash(); hash != hashes[5] {
+		t.Errorf("expected the state from 2 frames ago")
+	}
+	if c8.V[0] != 6 {
+		t.Errorf("V0 - expected: 6; got: %d", c8.V[0])
+	}
+
+	//Running on from the rewound state replaces the frames that were rewound.
+	res := c8.RunFrame()
+	if res.Err != nil {
+		t.Fatalf("failed to run frame: %v", res.Err)
+	}
+	if hash, _ := c8.StateHash(); hash != hashes[6] {
+		t.Errorf("expected running on to repeat the next frame")
+	}
+
+	//Going back further than the buffer stops at the oldest frame.
+	n, err = c8.Rewind.Back(c8, 10)
+	if err != nil || n != 4 {
+		t.Fatalf("expected to step back 4 frames; got: %d, %v", n, err)
+	}
+	if hash, _ := c8.StateHash(); hash != hashes[2] {
+		t.Errorf("expected the oldest state in the buffer")
+	}
+	if m, _ := c8.Memory.Read(0x300); m != 2 {
+		t.Errorf("memory - expected: 2; got: %d", m)
+	}
+	n, _ = c8.Rewind.Back(c8, 1)
+	if n != 0 {
+		t.Errorf("expected nothing left to rewind; got: %d", n)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tt := []struct {
+		name    string
+		earlier []byte
+		later   []byte
+		patches int
+	}{
+		{name: "Same", earlier: []byte{1, 2, 3}, later: []byte{1, 2, 3}, patches: 0},
+		{name: "Two runs", earlier: []byte{1, 2, 3, 4, 5}, later: []byte{9, 9, 3, 4, 9}, patches: 2},
+		{name: "Grew", earlier: []byte{1, 2}, later: []byte{1, 2, 3, 4}, patches: 0},
+		{name: "Shrank", earlier: []byte{1, 2, 3, 4}, later: []byte{1, 2}, patches: 1},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			d := diff(tc.earlier, tc.later)
+			if len(d.patches) != tc.patches {
+				t.Errorf("expected %d patches; got: %d", tc.patches, len(d.patches))
+			}
+			got := d.apply(tc.later)
+			if string(got) != string(tc.earlier) {
+				t.Errorf("expected: %v; got: %v", tc.earlier, got)
+			}
+		})
+	}
+}
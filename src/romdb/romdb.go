@@ -0,0 +1,89 @@
+//Package romdb holds recommended settings for known ROMs, looked up by the SHA-1 of the program.
+//The database is roms.json, embedded into the binary. To add a ROM, add an entry keyed by the
+//hash printed by sha1sum.
+package romdb
+
+import (
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+//go:embed roms.json
+var data []byte
+
+//Entry is the metadata for a single ROM.
+type Entry struct {
+	Title string `json:"title"`
+	//Platform is the name of the instruction set, see chip8.ParsePlatform.
+	Platform string `json:"platform"`
+	//IPS is the recommended clock speed, zero for the default.
+	IPS int `json:"ips,omitempty"`
+	//Quirks is the name of the quirks preset, see chip8.LookupQuirks. Empty for none.
+	Quirks string `json:"quirks,omitempty"`
+	//Keys describes what each key does, keyed by the hex digit of the key.
+	Keys map[string]string `json:"keys,omitempty"`
+}
+
+//KeyHints returns the key descriptions as "key: description" lines, in key order.
+func (e Entry) KeyHints() []string {
+	keys := make([]string, 0, len(e.Keys))
+	for k := range e.Keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := strconv.ParseUint(keys[i], 16, 8)
+		b, _ := strconv.ParseUint(keys[j], 16, 8)
+		return a < b
+	})
+
+	hints := make([]string, len(keys))
+	for i, k := range keys {
+		hints[i] = fmt.Sprintf("%s: %s", k, e.Keys[k])
+	}
+	return hints
+}
+
+var db = mustLoad(data)
+
+func load(b []byte) (map[string]Entry, error) {
+	entries := make(map[string]Entry)
+	err := json.Unmarshal(b, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode rom database: %v", err)
+	}
+	return entries, nil
+}
+
+func mustLoad(b []byte) map[string]Entry {
+	entries, err := load(b)
+	if err != nil {
+		panic(err)
+	}
+	return entries
+}
+
+//Hash returns the hex SHA-1 of the program, the key used in the database.
+func Hash(program []byte) string {
+	sum := sha1.Sum(program)
+	return hex.EncodeToString(sum[:])
+}
+
+//Lookup returns the entry for the program, false if it isn't a known ROM.
+func Lookup(program []byte) (Entry, bool) {
+	e, ok := db[Hash(program)]
+	return e, ok
+}
+
+//Entries returns a copy of every entry, keyed by hash.
+func Entries() map[string]Entry {
+	entries := make(map[string]Entry, len(db))
+	for hash, e := range db {
+		entries[hash] = e
+	}
+	return entries
+}
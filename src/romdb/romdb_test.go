@@ -0,0 +1,84 @@
+package romdb
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	chip8 "github.com/Neffats/Chip8/src"
+)
+
+func TestEntries(t *testing.T) {
+	for hash, e := range Entries() {
+		if len(hash) != 40 {
+			t.Errorf("%s - hash should be 40 hex digits", hash)
+		}
+		if e.Title == "" {
+			t.Errorf("%s - missing title", hash)
+		}
+		_, err := chip8.ParsePlatform(e.Platform)
+		if err != nil {
+			t.Errorf("%s - %v", hash, err)
+		}
+		if e.Quirks != "" {
+			_, err = chip8.LookupQuirks(e.Quirks)
+			if err != nil {
+				t.Errorf("%s - %v", hash, err)
+			}
+		}
+		if e.IPS < 0 {
+			t.Errorf("%s - negative ips %d", hash, e.IPS)
+		}
+		for k := range e.Keys {
+			if key, err := strconv.ParseUint(k, 16, 8); err != nil || key > 0xF {
+				t.Errorf("%s - key %q isn't a hex digit", hash, k)
+			}
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tt := []struct {
+		file  string
+		title string
+	}{
+		{file: "IBM.ch8", title: "IBM Logo"},
+		{file: "BREAKOUT.ch8", title: "Breakout (Carmelo Cortez, 1979)"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.file, func(t *testing.T) {
+			program, err := ioutil.ReadFile(filepath.Join("..", "..", "examples", tc.file))
+			if err != nil {
+				t.Fatalf("failed to read example: %v", err)
+			}
+			e, ok := Lookup(program)
+			if !ok {
+				t.Fatalf("%s (%s) not found", tc.file, Hash(program))
+			}
+			if e.Title != tc.title {
+				t.Errorf("title - expected: %q; got: %q", tc.title, e.Title)
+			}
+		})
+	}
+
+	_, ok := Lookup([]byte{0x12, 0x00})
+	if ok {
+		t.Errorf("expected an unknown program not to be found")
+	}
+}
+
+func TestKeyHints(t *testing.T) {
+	e := Entry{Keys: map[string]string{"A": "fire", "4": "left", "6": "right"}}
+	expected := []string{"4: left", "6: right", "A: fire"}
+	got := e.KeyHints()
+	if len(got) != len(expected) {
+		t.Fatalf("expected: %v; got: %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected: %v; got: %v", expected, got)
+		}
+	}
+}
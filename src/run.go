@@ -0,0 +1,64 @@
+//go:build !nosdl
+// +build !nosdl
+
+package chip8
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+//Run is the main loop for the Chip8 emulator.
+//It runs a frame at a time at 60Hz, painting the screen and handling SDL events in between.
+func (c *CPU) Run() error {
+	frame := time.NewTicker(time.Second / FrameRate)
+	defer frame.Stop()
+
+	running := true
+	for running {
+		if c.rewinding {
+			_, err := c.StepBack(1)
+			if err != nil {
+				fmt.Printf("Could not rewind: %v\n", err)
+				c.rewinding = false
+			}
+		}
+		if !c.rewinding {
+			res := c.RunFrame()
+			if c.Halted {
+				return nil
+			}
+			if res.Err != nil {
+				return res.Err
+			}
+		}
+
+		err := c.G.PaintSurface()
+		if err != nil {
+			return fmt.Errorf("could not paint surface: %v", err)
+		}
+		if c.Until != 0 && c.Cycles >= c.Until {
+			return nil
+		}
+
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch t := event.(type) {
+			case *sdl.QuitEvent:
+				println("Quit")
+				running = false
+				break
+			case *sdl.KeyboardEvent:
+				if t.Keysym.Sym == HotkeyRewind {
+					c.rewinding = t.Type == sdl.KEYDOWN
+				} else if t.Type == sdl.KEYDOWN && t.Repeat == 0 {
+					c.hotkey(t.Keysym.Sym)
+				}
+			}
+		}
+
+		<-frame.C
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package chip8
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	//stateMagic starts every save state file.
+	stateMagic = "C8ST"
+	//StateVersion is the save state format version, bump it whenever State changes.
+	StateVersion = uint16(5)
+)
+
+//State is a snapshot of the whole machine.
+type State struct {
+	PC     uint16
+	I      uint16
+	V      [16]uint8
+	Stack  [16]uint16
+	SP     uint8
+	Cycles uint64
+	RPL    [16]uint8
+	Halted bool
+
+	Pattern [16]byte
+	Pitch   uint8
+	//PatternLoaded is set if F002 has loaded Pattern, otherwise the beeper's tone is left alone.
+	PatternLoaded bool
+
+	//Rand is the position of the random number generator, nil if it isn't a SeededRandom.
+	Rand *uint64
+
+	DT uint
+	ST uint
+
+	Memory []byte
+	Screen Frame
+	//Planes is the bit mask of selected drawing planes.
+	Planes uint8
+	//Keys is the keypad state, bit n set for key n.
+	Keys uint16
+}
+
+//State takes a snapshot of the machine.
+func (c *CPU) State() (*State, error) {
+	dt, err := c.DT.Get()
+	if err != nil {
+		return nil, fmt.Errorf("could not get delay timer: %v", err)
+	}
+	st, err := c.ST.Get()
+	if err != nil {
+		return nil, fmt.Errorf("could not get sound timer: %v", err)
+	}
+
+	var random *uint64
+	if r, ok := c.Rand.(*SeededRandom); ok {
+		state := r.State()
+		random = &state
+	}
+
+	return &State{
+		PC:            c.PC,
+		I:             c.I,
+		V:             c.V,
+		Stack:         c.Stack,
+		SP:            c.SP,
+		Cycles:        c.Cycles,
+		RPL:           c.RPL,
+		Halted:        c.Halted,
+		Pattern:       c.Pattern,
+		Pitch:         c.Pitch,
+		PatternLoaded: c.PatternLoaded,
+		Rand:          random,
+		DT:            dt,
+		ST:            st,
+		Memory:        c.Memory.Snapshot(),
+		Screen:        c.G.Frame(),
+		Planes:        c.G.Planes(),
+		Keys:          c.Input.State(),
+	}, nil
+}
+
+//Restore puts the machine back to a snapshot taken by State.
+func (c *CPU) Restore(s *State) error {
+	err := c.Memory.Restore(s.Memory)
+	if err != nil {
+		return fmt.Errorf("could not restore memory: %v", err)
+	}
+	err = c.G.SetFrame(s.Screen)
+	if err != nil {
+		return fmt.Errorf("could not restore screen: %v", err)
+	}
+	err = c.G.SetPlanes(s.Planes)
+	if err != nil {
+		return fmt.Errorf("could not restore planes: %v", err)
+	}
+	err = c.Input.SetState(s.Keys)
+	if err != nil {
+		return fmt.Errorf("could not restore keypad: %v", err)
+	}
+	err = c.DT.Set(s.DT)
+	if err != nil {
+		return fmt.Errorf("could not restore delay timer: %v", err)
+	}
+	err = c.ST.Set(s.ST)
+	if err != nil {
+		return fmt.Errorf("could not restore sound timer: %v", err)
+	}
+
+	c.PC = s.PC
+	c.I = s.I
+	c.V = s.V
+	c.Stack = s.Stack
+	c.SP = s.SP
+	c.Cycles = s.Cycles
+	c.RPL = s.RPL
+	c.Halted = s.Halted
+	c.Pattern = s.Pattern
+	c.Pitch = s.Pitch
+	c.PatternLoaded = s.PatternLoaded
+	c.nextDraw = 0
+	if s.Rand != nil {
+		r := NewSeededRandom(0)
+		r.SetState(*s.Rand)
+		c.Rand = r
+	}
+
+	//An all zero pattern would replace the default tone with silence, so only restore one F002 loaded.
+	if c.PatternLoaded {
+		err = c.Sound.SetPattern(c.Pattern, c.Pitch)
+		if err != nil {
+			return fmt.Errorf("could not restore audio pattern: %v", err)
+		}
+	}
+
+	return c.UpdateSound()
+}
+
+//Save writes a save state: the magic "C8ST", a big endian uint16 version, then the gob encoded State.
+func (c *CPU) Save(w io.Writer) error {
+	s, err := c.State()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, stateMagic)
+	if err != nil {
+		return fmt.Errorf("could not write save state header: %v", err)
+	}
+	err = binary.Write(w, binary.BigEndian, StateVersion)
+	if err != nil {
+		return fmt.Errorf("could not write save state header: %v", err)
+	}
+	err = gob.NewEncoder(w).Encode(s)
+	if err != nil {
+		return fmt.Errorf("could not encode save state: %v", err)
+	}
+	return nil
+}
+
+//Load reads a save state written by Save and restores it.
+func (c *CPU) Load(r io.Reader) error {
+	magic := make([]byte, len(stateMagic))
+	_, err := io.ReadFull(r, magic)
+	if err != nil || string(magic) != stateMagic {
+		return fmt.Errorf("not a save state")
+	}
+	var version uint16
+	err = binary.Read(r, binary.BigEndian, &version)
+	if err != nil {
+		return fmt.Errorf("could not read save state version: %v", err)
+	}
+	if version != StateVersion {
+		return fmt.Errorf("unsupported save state version %d, expected %d", version, StateVersion)
+	}
+
+	var s State
+	err = gob.NewDecoder(r).Decode(&s)
+	if err != nil {
+		return fmt.Errorf("could not decode save state: %v", err)
+	}
+	return c.Restore(&s)
+}
+
+//SaveFile writes a save state to the named file.
+func (c *CPU) SaveFile(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("could not create save state: %v", err)
+	}
+	defer f.Close()
+
+	err = c.Save(f)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+//LoadFile restores a save state from the named file.
+func (c *CPU) LoadFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("could not open save state: %v", err)
+	}
+	defer f.Close()
+
+	return c.Load(f)
+}
@@ -0,0 +1,139 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	c8 := setup()
+	c8.Init()
+	//LD V0, 5; LD V1, 7; LD I, 0x00; DRW V0, V1, 5; CALL 0x20A; LD V2, 1
+	c8.LoadProgram([]byte{0x60, 0x05, 0x61, 0x07, 0xA0, 0x00, 0xD0, 0x15, 0x22, 0x0A, 0x62, 0x01})
+	res := c8.RunCycles(5)
+	if res.Err != nil {
+		t.Fatalf("failed to run: %v", res.Err)
+	}
+	c8.DT.Set(42)
+	c8.ST.Set(3)
+	c8.Input.SetState(1<<0x4 | 1<<0xB)
+	c8.Seed(99)
+	c8.Rand.Byte()
+
+	var buf bytes.Buffer
+	err := c8.Save(&buf)
+	if err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	expected, _ := c8.State()
+
+	restored := setup()
+	err = restored.Load(&buf)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	got, _ := restored.State()
+
+	if got.PC != expected.PC || got.I != expected.I || got.SP != expected.SP || got.Cycles != expected.Cycles {
+		t.Errorf("registers - expected: %+v; got: %+v", expected, got)
+	}
+	if got.V != expected.V || got.Stack != expected.Stack {
+		t.Errorf("V/stack - expected: %v %v; got: %v %v", expected.V, expected.Stack, got.V, got.Stack)
+	}
+	if got.DT != 42 || got.ST != 3 {
+		t.Errorf("timers - expected: 42, 3; got: %d, %d", got.DT, got.ST)
+	}
+	if got.Keys != expected.Keys {
+		t.Errorf("keys - expected: %x; got: %x", expected.Keys, got.Keys)
+	}
+	if !bytes.Equal(got.Memory, expected.Memory) {
+		t.Errorf("memory doesn't match")
+	}
+	if !bytes.Equal(got.Screen.Pix, expected.Screen.Pix) {
+		t.Errorf("screen doesn't match")
+	}
+	if p, _ := restored.G.Pixel(5, 7); p != 1 {
+		t.Errorf("expected the drawn sprite to be restored")
+	}
+
+	//Carries on from where the original left off.
+	res = restored.Step()
+	if res.Err != nil || restored.V[2] != 1 {
+		t.Errorf("expected restored machine to keep running; err: %v; V2: %d", res.Err, restored.V[2])
+	}
+	if got, expected := restored.Rand.Byte(), c8.Rand.Byte(); got != expected {
+		t.Errorf("random numbers - expected: %x; got: %x", expected, got)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tt := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Empty", data: []byte{}},
+		{name: "Bad magic", data: []byte("NOPE\x00\x01")},
+		{name: "Future version", data: []byte("C8ST\x00\xFF")},
+		{name: "Truncated", data: []byte("C8ST\x00\x01\x12")},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c8 := setup()
+			err := c8.Load(bytes.NewReader(tc.data))
+			if err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+//patternBeeper counts the calls to SetPattern.
+type patternBeeper struct {
+	*RecordingBeeper
+	patterns int
+}
+
+func (b *patternBeeper) SetPattern(pattern [16]byte, pitch uint8) error {
+	b.patterns++
+	return b.RecordingBeeper.SetPattern(pattern, pitch)
+}
+
+//Restoring a state without an XO-CHIP audio pattern leaves the beeper's tone alone.
+func TestRestorePattern(t *testing.T) {
+	c8 := setup()
+	s, err := c8.State()
+	if err != nil {
+		t.Fatalf("failed to get state: %v", err)
+	}
+
+	restored := setup()
+	b := &patternBeeper{RecordingBeeper: NewRecordingBeeper()}
+	restored.Sound = b
+	err = restored.Restore(s)
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if b.patterns != 0 {
+		t.Errorf("expected SetPattern not to be called; called %d times", b.patterns)
+	}
+
+	//Once F002 has loaded one, the pattern comes back with the state.
+	c8.Memory.Write(0xF0, 0x300)
+	c8.I = 0x300
+	err = c8.LoadAudio(0xF002)
+	if err != nil {
+		t.Fatalf("failed to load audio pattern: %v", err)
+	}
+	s, err = c8.State()
+	if err != nil {
+		t.Fatalf("failed to get state: %v", err)
+	}
+	err = restored.Restore(s)
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if b.patterns != 1 || b.Pattern != c8.Pattern {
+		t.Errorf("expected pattern %x to be set once; got %x after %d calls", c8.Pattern, b.Pattern, b.patterns)
+	}
+}
@@ -0,0 +1,204 @@
+package chip8
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//ScreenshotScale is the size of a low-res pixel in screenshots, hi-res pixels are half the size.
+const ScreenshotScale = 10
+
+//asciiPixels are the characters WriteASCII uses for each pixel value: unlit, plane 1, plane 2 and both.
+const asciiPixels = ".#+@"
+
+//WritePNG encodes the image as a PNG with every pixel scaled up to a scale x scale square.
+func WritePNG(w io.Writer, img image.Image, scale int) error {
+	if scale < 1 {
+		return fmt.Errorf("scale must be positive, got %d", scale)
+	}
+	b := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, b.Dx()*scale, b.Dy()*scale))
+	for y := 0; y < scaled.Rect.Dy(); y++ {
+		for x := 0; x < scaled.Rect.Dx(); x++ {
+			scaled.Set(x, y, img.At(b.Min.X+x/scale, b.Min.Y+y/scale))
+		}
+	}
+
+	err := png.Encode(w, scaled)
+	if err != nil {
+		return fmt.Errorf("could not encode png: %v", err)
+	}
+	return nil
+}
+
+//WritePBM writes the frame as a binary netpbm bitmap, lit pixels in any plane are black.
+func WritePBM(w io.Writer, frame Frame) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P4\n%d %d\n", frame.W, frame.H)
+
+	//Each row is packed 8 pixels to a byte, most significant bit first.
+	row := make([]byte, (frame.W+7)/8)
+	for y := 0; y < frame.H; y++ {
+		for i := range row {
+			row[i] = 0
+		}
+		for x := 0; x < frame.W; x++ {
+			if frame.At(x, y) != 0 {
+				row[x/8] |= 0x80 >> uint(x%8)
+			}
+		}
+		bw.Write(row)
+	}
+
+	err := bw.Flush()
+	if err != nil {
+		return fmt.Errorf("could not write pbm: %v", err)
+	}
+	return nil
+}
+
+//WriteASCII writes the frame as text, one line per row.
+//Unlit pixels are '.', lit ones '#', and XO-CHIP's plane 2 and both planes are '+' and '@'.
+func WriteASCII(w io.Writer, frame Frame) error {
+	bw := bufio.NewWriter(w)
+	for y := 0; y < frame.H; y++ {
+		for x := 0; x < frame.W; x++ {
+			bw.WriteByte(asciiPixels[frame.At(x, y)&3])
+		}
+		bw.WriteByte('\n')
+	}
+
+	err := bw.Flush()
+	if err != nil {
+		return fmt.Errorf("could not write text: %v", err)
+	}
+	return nil
+}
+
+//SaveScreenshot writes the frame to a file, picking the format from the extension:
+//.png in the palette's colours, .pbm, or .txt for ASCII art.
+func SaveScreenshot(name string, frame Frame, p Palette) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("could not create screenshot: %v", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".png":
+		err = WritePNG(f, Render(frame, p), screenshotScale(frame.W))
+	case ".pbm":
+		err = WritePBM(f, frame)
+	case ".txt":
+		err = WriteASCII(f, frame)
+	default:
+		err = fmt.Errorf("unknown screenshot format %q, expected .png, .pbm or .txt", ext)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(name)
+		return err
+	}
+	return f.Close()
+}
+
+//SavePNG writes an image of the screen, one image pixel per screen pixel, to a PNG scaled the same as screenshots.
+//Used for frames that have been through a Filter, which SaveScreenshot can't draw.
+func SavePNG(name string, img image.Image) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("could not create screenshot: %v", err)
+	}
+	err = WritePNG(f, img, screenshotScale(img.Bounds().Dx()))
+	if err != nil {
+		f.Close()
+		os.Remove(name)
+		return err
+	}
+	return f.Close()
+}
+
+//screenshotScale keeps screenshots of a screen w pixels wide the same size in either resolution.
+func screenshotScale(w int) int {
+	scale := ScreenshotScale * ScreenWidth / w
+	if scale < 1 {
+		return 1
+	}
+	return scale
+}
+
+//FrameDumper is a FrameTap that writes every Every'th frame to a numbered PNG in Dir.
+type FrameDumper struct {
+	Dir   string
+	Every uint64
+
+	Palette Palette
+	//Filter, if set, is applied to every frame, including the ones that aren't written.
+	Filter Filter
+}
+
+//NewFrameDumper returns a dumper writing every n'th frame to dir in the default palette.
+func NewFrameDumper(dir string, n uint64) *FrameDumper {
+	return &FrameDumper{Dir: dir, Every: n, Palette: palettes[DefaultPalette]}
+}
+
+//TapFrame writes the frame to Dir as frame-NNNNNN.png if it's one of the frames to keep.
+func (d *FrameDumper) TapFrame(n uint64, frame Frame) error {
+	var img *image.RGBA
+	if d.Filter != nil {
+		img = d.Filter.Apply(frame, d.Palette)
+	}
+	if d.Every > 1 && n%d.Every != 0 {
+		return nil
+	}
+	if img == nil {
+		img = Render(frame, d.Palette)
+	}
+
+	name := filepath.Join(d.Dir, fmt.Sprintf("frame-%06d.png", n))
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("could not create frame dump: %v", err)
+	}
+	err = WritePNG(f, img, screenshotScale(frame.W))
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//paletted is a Display with colours, like Graphics.
+type paletted interface {
+	Palette() Palette
+}
+
+//Screenshot saves the screen as a PNG in the display's colours, numbered so it doesn't replace an earlier one.
+//Returns the name of the file written.
+func (c *CPU) Screenshot() (string, error) {
+	name := nextName(c.ScreenshotPath, ".png")
+	return name, SaveScreenshot(name, c.G.Frame(), c.palette())
+}
+
+//palette returns the display's colours, or the default palette if it doesn't have any.
+func (c *CPU) palette() Palette {
+	if g, ok := c.G.(paletted); ok {
+		return g.Palette()
+	}
+	return palettes[DefaultPalette]
+}
+
+//nextName returns the first of prefix-1.ext, prefix-2.ext and so on that doesn't exist yet.
+func nextName(prefix string, ext string) string {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s-%d%s", prefix, i, ext)
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			return name
+		}
+	}
+}
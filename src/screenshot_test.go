@@ -0,0 +1,144 @@
+package chip8
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//testFrame is a low-res frame with a few pixels lit.
+func testFrame() Frame {
+	f := Frame{W: ScreenWidth, H: ScreenHeight, Pix: make([]uint8, ScreenWidth*ScreenHeight)}
+	f.Pix[0] = 1
+	f.Pix[9] = 2
+	f.Pix[ScreenWidth+1] = 3
+	return f
+}
+
+func TestWritePNG(t *testing.T) {
+	var buf bytes.Buffer
+	p := palettes["octo"]
+	err := WritePNG(&buf, Render(testFrame(), p), 2)
+	if err != nil {
+		t.Fatalf("failed to write png: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode png: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != ScreenWidth*2 || b.Dy() != ScreenHeight*2 {
+		t.Fatalf("expected a %dx%d image; got: %v", ScreenWidth*2, ScreenHeight*2, b)
+	}
+
+	tt := []struct {
+		name  string
+		x, y  int
+		value uint8
+	}{
+		{name: "Lit", x: 1, y: 1, value: 1},
+		{name: "Unlit", x: 2, y: 0, value: 0},
+		{name: "Plane 2", x: 18, y: 1, value: 2},
+		{name: "Both planes", x: 3, y: 3, value: 3},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r, g, b, _ := img.At(tc.x, tc.y).RGBA()
+			c := p[tc.value]
+			if uint8(r>>8) != c.R || uint8(g>>8) != c.G || uint8(b>>8) != c.B {
+				t.Errorf("expected: %v; got: %v", c, img.At(tc.x, tc.y))
+			}
+		})
+	}
+}
+
+func TestWritePBM(t *testing.T) {
+	var buf bytes.Buffer
+	err := WritePBM(&buf, testFrame())
+	if err != nil {
+		t.Fatalf("failed to write pbm: %v", err)
+	}
+	header := "P4\n64 32\n"
+	got := buf.Bytes()
+	if !bytes.HasPrefix(got, []byte(header)) {
+		t.Fatalf("expected header %q; got: %q", header, got[:len(header)])
+	}
+	rows := got[len(header):]
+	if len(rows) != ScreenWidth/8*ScreenHeight {
+		t.Fatalf("expected %d bytes of pixels; got: %d", ScreenWidth/8*ScreenHeight, len(rows))
+	}
+	expected := []byte{0x80, 0x40, 0, 0, 0, 0, 0, 0, 0x40}
+	if !bytes.Equal(rows[:len(expected)], expected) {
+		t.Errorf("expected: % X; got: % X", expected, rows[:len(expected)])
+	}
+}
+
+func TestWriteASCII(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteASCII(&buf, testFrame())
+	if err != nil {
+		t.Fatalf("failed to write text: %v", err)
+	}
+	lines := bytes.Split(buf.Bytes(), []byte("\n"))
+	if len(lines) != ScreenHeight+1 {
+		t.Fatalf("expected %d lines; got: %d", ScreenHeight, len(lines)-1)
+	}
+	if got := string(lines[0][:10]); got != "#........+" {
+		t.Errorf("line 0 - expected: %q; got: %q", "#........+", got)
+	}
+	if got := string(lines[1][:3]); got != ".@." {
+		t.Errorf("line 1 - expected: %q; got: %q", ".@.", got)
+	}
+}
+
+func TestSaveScreenshot(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"shot.png", "shot.pbm", "shot.txt"} {
+		err := SaveScreenshot(filepath.Join(dir, name), testFrame(), palettes[DefaultPalette])
+		if err != nil {
+			t.Errorf("failed to save %s: %v", name, err)
+		}
+	}
+
+	name := filepath.Join(dir, "shot.bmp")
+	err := SaveScreenshot(name, testFrame(), palettes[DefaultPalette])
+	if err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("expected the failed screenshot to be removed")
+	}
+}
+
+func TestFrameDumper(t *testing.T) {
+	dir := t.TempDir()
+	c8 := setup()
+	c8.Taps = append(c8.Taps, NewFrameDumper(dir, 2))
+	//Jump on the spot forever.
+	err := c8.LoadProgram([]byte{0x12, 0x00})
+	if err != nil {
+		t.Fatalf("failed to load program: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		res := c8.RunFrame()
+		if res.Err != nil {
+			t.Fatalf("failed to run frame: %v", res.Err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.png"))
+	if err != nil {
+		t.Fatalf("failed to list frames: %v", err)
+	}
+	expected := []string{"frame-000002.png", "frame-000004.png"}
+	if len(files) != len(expected) {
+		t.Fatalf("expected frames %v; got: %v", expected, files)
+	}
+	for i, f := range files {
+		if filepath.Base(f) != expected[i] {
+			t.Errorf("expected: %s; got: %s", expected[i], filepath.Base(f))
+		}
+	}
+}
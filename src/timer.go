@@ -6,6 +6,7 @@ import (
 )
 
 //Timer decrements the timer value at a rate of 60Hz.
+//Wall-clock timers count down on their own, manual timers only count down when Step60Hz is called.
 type Timer struct {
 	timer  uint
 	mux    *sync.RWMutex
@@ -26,14 +27,44 @@ func NewTimer() *Timer {
 	return t
 }
 
+//NewManualTimer returns a pointer to a timer struct initialised at 0.
+//It has no ticker, the owner advances it with Step60Hz so runs are repeatable.
+func NewManualTimer() *Timer {
+	return &Timer{
+		timer: 0,
+		mux:   &sync.RWMutex{},
+	}
+}
+
+//Manual returns true if the timer is only advanced by Step60Hz.
+func (t *Timer) Manual() bool {
+	return t.ticker == nil
+}
+
+//Step60Hz decrements the timer by one 60Hz tick.
+//Does nothing on a wall-clock timer, its ticker is already doing that.
+func (t *Timer) Step60Hz() {
+	if !t.Manual() {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.timer != 0 {
+		t.timer--
+	}
+}
+
 //Tick is a goroutine that will decrement 1 from the timer at a rate of 60Hz.
 func (t *Timer) Tick() {
+	if t.Manual() {
+		return
+	}
 	for range t.ticker.C {
+		t.mux.Lock()
 		if t.timer != 0 {
-			t.mux.Lock()
 			t.timer--
-			t.mux.Unlock()
 		}
+		t.mux.Unlock()
 	}
 }
 
@@ -55,5 +86,8 @@ func (t *Timer) Get() (uint, error) {
 
 //Stop the ticker.
 func (t *Timer) Stop() {
+	if t.Manual() {
+		return
+	}
 	t.ticker.Stop()
 }
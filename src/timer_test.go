@@ -0,0 +1,53 @@
+package chip8
+
+import "testing"
+
+func TestManualTimer(t *testing.T) {
+	tt := []struct {
+		name     string
+		set      uint
+		steps    int
+		expected uint
+	}{
+		{name: "Counts down", set: 10, steps: 4, expected: 6},
+		{name: "Stops at zero", set: 2, steps: 5, expected: 0},
+		{name: "No steps", set: 60, steps: 0, expected: 60},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			timer := NewManualTimer()
+			timer.Set(tc.set)
+			for i := 0; i < tc.steps; i++ {
+				timer.Step60Hz()
+			}
+			got, _ := timer.Get()
+			if got != tc.expected {
+				t.Errorf("expected: %d; got: %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCPUStep60Hz(t *testing.T) {
+	c8 := setup()
+	b := NewRecordingBeeper()
+	c8.Sound = b
+	c8.DT.Set(3)
+	c8.ST.Set(2)
+	c8.UpdateSound()
+
+	for i := 0; i < 3; i++ {
+		err := c8.Step60Hz()
+		if err != nil {
+			t.Fatalf("failed to step timers: %v", err)
+		}
+	}
+
+	if dt, _ := c8.DT.Get(); dt != 0 {
+		t.Errorf("DT - expected: 0; got: %d", dt)
+	}
+	if len(b.Events) != 2 || !b.Events[0].On || b.Events[1].On {
+		t.Errorf("expected beep to start and stop; got: %v", b.Events)
+	}
+}